@@ -0,0 +1,205 @@
+package hyper
+
+import (
+	"mime"
+	"net/http"
+	"os"
+	"path"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// Precompressed serves the .br and .gz sidecar files, e.g. app.js.br
+// for app.js, to clients that accept the matching content encoding.
+func Precompressed() FileOption {
+	return func(server *fileServer) {
+		server.precompressed = true
+	}
+}
+
+// Fingerprinted builds a manifest of the content-hashed files, e.g.
+// app.3f9a1c.js, when the route is registered. The fingerprinted
+// files are served with immutable, long-lived cache headers,
+// and their paths are resolved by router.Asset.
+func Fingerprinted() FileOption {
+	return func(server *fileServer) {
+		server.fingerprinted = true
+	}
+}
+
+// immutableCacheControl is sent with fingerprinted files,
+// which never change under the same name.
+const immutableCacheControl = "public, max-age=31536000, immutable"
+
+// precompressedEncodings lists the supported sidecar encodings,
+// in the order of preference.
+var precompressedEncodings = []struct {
+	encoding, extension string
+}{
+	{"br", ".br"},
+	{"gzip", ".gz"},
+}
+
+// fingerprintPattern matches names with a content hash before
+// the extension, e.g. app.3f9a1c.js.
+var fingerprintPattern = regexp.MustCompile(`^(.+)\.([0-9a-f]{6,64})(\.[^.]+)$`)
+
+// Asset returns the path of the fingerprinted version of the file,
+// e.g. /static/app.3f9a1c.js for app.js, so templates can link to
+// assets by their original names.
+//
+// If the file is not fingerprinted, the path of the original file,
+// under the first route registered with the Fingerprinted option,
+// is returned.
+func (r *Router) Asset(name string) string {
+	name = path.Clean("/" + name)
+
+	for _, server := range r.assets {
+		if hashed, ok := server.manifest.files[name]; ok {
+			return server.prefix + hashed[1:]
+		}
+	}
+
+	if len(r.assets) > 0 {
+		return r.assets[0].prefix + name[1:]
+	}
+
+	return name
+}
+
+// assetManifest maps the original file names to their fingerprinted versions.
+type assetManifest struct {
+	files  map[string]string
+	hashed map[string]bool
+	// modTimes are used to select the latest build of the file,
+	// when there are several fingerprinted versions of it.
+	modTimes map[string]int64
+}
+
+// buildManifest walks the file system and collects all fingerprinted files.
+func buildManifest(root http.FileSystem) (*assetManifest, error) {
+	manifest := &assetManifest{
+		files:    make(map[string]string),
+		hashed:   make(map[string]bool),
+		modTimes: make(map[string]int64),
+	}
+
+	return manifest, manifest.walk(root, "/")
+}
+
+func (manifest *assetManifest) walk(root http.FileSystem, dir string) error {
+	f, err := root.Open(dir)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	entries, err := f.Readdir(-1)
+	if err != nil {
+		return err
+	}
+
+	for _, entry := range entries {
+		name := path.Join(dir, entry.Name())
+
+		if entry.IsDir() {
+			if err := manifest.walk(root, name); err != nil {
+				return err
+			}
+
+			continue
+		}
+
+		match := fingerprintPattern.FindStringSubmatch(entry.Name())
+		if match == nil {
+			continue
+		}
+
+		// Every fingerprinted version is immutable, not only the latest.
+		manifest.hashed[name] = true
+
+		original := path.Join(dir, match[1]+match[3])
+		if modTime, ok := manifest.modTimes[original]; ok && modTime > entry.ModTime().UnixNano() {
+			continue
+		}
+
+		manifest.files[original] = name
+		manifest.modTimes[original] = entry.ModTime().UnixNano()
+	}
+
+	return nil
+}
+
+// serveAsset serves the file, adding the cache headers for the
+// fingerprinted files and preferring the precompressed sidecars.
+func (server *fileServer) serveAsset(w http.ResponseWriter, req *http.Request, name string, f http.File, stat os.FileInfo) {
+	if server.manifest != nil && server.manifest.hashed[name] {
+		w.Header().Set("Cache-Control", immutableCacheControl)
+	}
+
+	if server.precompressed {
+		w.Header().Add("Vary", "Accept-Encoding")
+
+		for _, sidecar := range precompressedEncodings {
+			if !acceptsEncoding(req, sidecar.encoding) {
+				continue
+			}
+
+			compressed, err := server.root.Open(name + sidecar.extension)
+			if err != nil {
+				continue
+			}
+			defer compressed.Close()
+
+			compressedStat, err := compressed.Stat()
+			if err != nil || compressedStat.IsDir() {
+				continue
+			}
+
+			contentType := mime.TypeByExtension(path.Ext(name))
+			if contentType == "" {
+				contentType = "application/octet-stream"
+			}
+
+			w.Header().Set("Content-Type", contentType)
+			w.Header().Set("Content-Encoding", sidecar.encoding)
+			serveFile(w, req, compressed, compressedStat)
+			return
+		}
+	}
+
+	serveFile(w, req, f, stat)
+}
+
+// acceptsEncoding checks if the Accept-Encoding header of the request
+// allows the encoding. The encoding listed by its name takes precedence
+// over the '*' coding, e.g. "*;q=0, gzip" accepts only gzip.
+func acceptsEncoding(req *http.Request, encoding string) bool {
+	wildcard := false
+
+	for _, value := range strings.Split(req.Header.Get("Accept-Encoding"), ",") {
+		coding, quality, _ := strings.Cut(strings.TrimSpace(value), ";")
+		coding = strings.TrimSpace(coding)
+
+		if coding != encoding && coding != "*" {
+			continue
+		}
+
+		accepted := true
+		quality = strings.TrimSpace(quality)
+		if q, ok := strings.CutPrefix(quality, "q="); ok {
+			if weight, err := strconv.ParseFloat(q, 64); err == nil && weight == 0 {
+				accepted = false
+			}
+		}
+
+		if coding == encoding {
+			return accepted
+		}
+
+		wildcard = accepted
+	}
+
+	return wildcard
+}
@@ -0,0 +1,94 @@
+package hyper
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"testing/fstest"
+	"time"
+)
+
+var testAssets = fstest.MapFS{
+	"app.js":              {Data: []byte("plain")},
+	"app.js.br":           {Data: []byte("brotli")},
+	"app.js.gz":           {Data: []byte("gzip")},
+	"js/app.0a1b2c.js":    {Data: []byte("old"), ModTime: time.Unix(1, 0)},
+	"js/app.3f9a1c.js":    {Data: []byte("new"), ModTime: time.Unix(2, 0)},
+	"css/site.d4e5f6.css": {Data: []byte("css")},
+	// The older version of lib.js is walked after the newer one.
+	"js/lib.00aa11.js": {Data: []byte("new"), ModTime: time.Unix(2, 0)},
+	"js/lib.ff0011.js": {Data: []byte("old"), ModTime: time.Unix(1, 0)},
+}
+
+func TestRouterPrecompressed(t *testing.T) {
+	router := NewRouter()
+	router.ServeFS("/static/*filepath", testAssets, Precompressed())
+
+	tests := []struct {
+		acceptEncoding string
+		body, encoding string
+	}{
+		{"", "plain", ""},
+		{"gzip", "gzip", "gzip"},
+		{"gzip, br", "brotli", "br"},
+		{"br;q=0, gzip", "gzip", "gzip"},
+		{"*;q=0, gzip", "gzip", "gzip"},
+		{"gzip;q=0, *", "brotli", "br"},
+		{"*;q=0", "plain", ""},
+	}
+
+	for _, test := range tests {
+		req := httptest.NewRequest(http.MethodGet, "/static/app.js", nil)
+		req.Header.Set("Accept-Encoding", test.acceptEncoding)
+
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+
+		if w.Body.String() != test.body || w.Header().Get("Content-Encoding") != test.encoding {
+			t.Errorf(
+				"GET /static/app.js (Accept-Encoding: %s): got ('%s', '%s'), wanted ('%s', '%s')",
+				test.acceptEncoding, w.Body.String(), w.Header().Get("Content-Encoding"), test.body, test.encoding,
+			)
+		}
+
+		if contentType := w.Header().Get("Content-Type"); contentType != "text/javascript; charset=utf-8" {
+			t.Errorf("GET /static/app.js (Accept-Encoding: %s): got Content-Type '%s'", test.acceptEncoding, contentType)
+		}
+	}
+}
+
+func TestRouterFingerprinted(t *testing.T) {
+	router := NewRouter()
+	router.ServeFS("/static/*filepath", testAssets, Fingerprinted())
+
+	tests := []struct {
+		name, want string
+	}{
+		{"js/app.js", "/static/js/app.3f9a1c.js"},
+		{"/css/site.css", "/static/css/site.d4e5f6.css"},
+		{"app.js", "/static/app.js"},
+		{"js/lib.js", "/static/js/lib.00aa11.js"},
+	}
+
+	for _, test := range tests {
+		if got := router.Asset(test.name); got != test.want {
+			t.Errorf("router.Asset('%s'): got '%s', wanted '%s'", test.name, got, test.want)
+		}
+	}
+
+	for _, path := range []string{"/static/js/app.3f9a1c.js", "/static/js/app.0a1b2c.js", "/static/js/lib.ff0011.js"} {
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, httptest.NewRequest(http.MethodGet, path, nil))
+
+		if got := w.Header().Get("Cache-Control"); got != immutableCacheControl {
+			t.Errorf("GET %s: got Cache-Control '%s', wanted '%s'", path, got, immutableCacheControl)
+		}
+	}
+
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/static/app.js", nil))
+
+	if got := w.Header().Get("Cache-Control"); got != "" {
+		t.Errorf("GET /static/app.js: got Cache-Control '%s', wanted none", got)
+	}
+}
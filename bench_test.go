@@ -0,0 +1,230 @@
+package hyper
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+// allocationBudgets are the maximum allocations per request served
+// through router.ServeHTTP, for the regular and the frozen router.
+// The tests fail when a change makes the routing allocate more,
+// so the regressions are caught early.
+var allocationBudgets = []struct {
+	name   string
+	routes []testRoute
+	method string
+	path   string
+	budget float64
+	frozen float64
+}{
+	{"GitHubStatic", githubAPI, "GET", "/user/repos", 2, 2},
+	{"GitHubParam", githubAPI, "GET", "/repos/julienschmidt/httprouter/stargazers", 8, 5},
+	{"GitHub3Params", githubAPI, "GET", "/repos/julienschmidt/httprouter/issues/42/labels", 11, 5},
+	{"ParseStatic", parseAPI, "GET", "/1/users", 2, 2},
+	{"Parse2Params", parseAPI, "GET", "/1/classes/go/123456789", 8, 5},
+	{"GPlusStatic", googlePlusAPI, "GET", "/people", 2, 2},
+	{"GPlus2Params", googlePlusAPI, "GET", "/people/118051310819094153327/activities/123456789", 8, 5},
+	{"CatchAll", []testRoute{{"GET", "/static/*filepath"}}, "GET", "/static/css/site.css", 5, 5},
+}
+
+// discardWriter is a http.ResponseWriter that does not record
+// anything, so it does not affect the allocation counts.
+type discardWriter struct {
+	header http.Header
+}
+
+func (w *discardWriter) Header() http.Header         { return w.header }
+func (w *discardWriter) Write(b []byte) (int, error) { return len(b), nil }
+func (w *discardWriter) WriteHeader(int)             {}
+
+func loadRouter(routes []testRoute) *Router {
+	router := NewRouter()
+	for _, route := range routes {
+		router.Handle(route.method, route.path, emptyHandler)
+	}
+
+	return router
+}
+
+// requestPath replaces the parameters of the route with sample values.
+func requestPath(route string) string {
+	segments := strings.Split(route, "/")
+	for i, segment := range segments {
+		if segment != "" && (segment[0] == ':' || segment[0] == '*') {
+			segments[i] = "value"
+		}
+	}
+
+	return strings.Join(segments, "/")
+}
+
+func TestAPIRouteSets(t *testing.T) {
+	sets := map[string][]testRoute{
+		"GitHub": githubAPI,
+		"Parse":  parseAPI,
+		"GPlus":  googlePlusAPI,
+	}
+
+	for name, routes := range sets {
+		router := loadRouter(routes)
+
+		for _, route := range routes {
+			path := requestPath(route.path)
+			if got, _ := router.getRoute(emptyRequest().Context(), route.method, path); got == nil || got.Pattern != route.path {
+				t.Errorf("%s: %s %s: got route %v, wanted '%s'", name, route.method, path, got, route.path)
+			}
+		}
+	}
+}
+
+func TestAllocationBudgets(t *testing.T) {
+	for _, test := range allocationBudgets {
+		for _, frozen := range []bool{false, true} {
+			router := loadRouter(test.routes)
+			budget := test.budget
+			if frozen {
+				router.Freeze()
+				budget = test.frozen
+			}
+
+			w := &discardWriter{header: make(http.Header)}
+			req := httptest.NewRequest(test.method, test.path, nil)
+
+			allocs := testing.AllocsPerRun(100, func() {
+				router.ServeHTTP(w, req)
+			})
+
+			if allocs > budget {
+				t.Errorf("%s (frozen: %v): got %v allocations per request, budget is %v", test.name, frozen, allocs, budget)
+			}
+		}
+	}
+}
+
+func emptyRequest() *http.Request {
+	return httptest.NewRequest(http.MethodGet, "/", nil)
+}
+
+func benchmarkRoutes(b *testing.B, routes []testRoute, method, path string, frozen bool) {
+	router := loadRouter(routes)
+	if frozen {
+		router.Freeze()
+	}
+
+	w := &discardWriter{header: make(http.Header)}
+	req := httptest.NewRequest(method, path, nil)
+
+	b.ReportAllocs()
+	b.ResetTimer()
+
+	for i := 0; i < b.N; i++ {
+		router.ServeHTTP(w, req)
+	}
+}
+
+func benchmarkAll(b *testing.B, routes []testRoute, frozen bool) {
+	router := loadRouter(routes)
+	if frozen {
+		router.Freeze()
+	}
+
+	w := &discardWriter{header: make(http.Header)}
+	requests := make([]*http.Request, len(routes))
+	for i, route := range routes {
+		requests[i] = httptest.NewRequest(route.method, requestPath(route.path), nil)
+	}
+
+	b.ReportAllocs()
+	b.ResetTimer()
+
+	for i := 0; i < b.N; i++ {
+		for _, req := range requests {
+			router.ServeHTTP(w, req)
+		}
+	}
+}
+
+func BenchmarkGitHubStatic(b *testing.B) {
+	benchmarkRoutes(b, githubAPI, "GET", "/user/repos", false)
+}
+
+func BenchmarkGitHubParam(b *testing.B) {
+	benchmarkRoutes(b, githubAPI, "GET", "/repos/julienschmidt/httprouter/stargazers", false)
+}
+
+func BenchmarkGitHubAll(b *testing.B) {
+	benchmarkAll(b, githubAPI, false)
+}
+
+func BenchmarkGitHubAllFrozen(b *testing.B) {
+	benchmarkAll(b, githubAPI, true)
+}
+
+func BenchmarkParseStatic(b *testing.B) {
+	benchmarkRoutes(b, parseAPI, "GET", "/1/users", false)
+}
+
+func BenchmarkParse1Param(b *testing.B) {
+	benchmarkRoutes(b, parseAPI, "GET", "/1/classes/go", false)
+}
+
+func BenchmarkParse2Params(b *testing.B) {
+	benchmarkRoutes(b, parseAPI, "GET", "/1/classes/go/123456789", false)
+}
+
+func BenchmarkParseAll(b *testing.B) {
+	benchmarkAll(b, parseAPI, false)
+}
+
+func BenchmarkParseAllFrozen(b *testing.B) {
+	benchmarkAll(b, parseAPI, true)
+}
+
+func BenchmarkGPlusStatic(b *testing.B) {
+	benchmarkRoutes(b, googlePlusAPI, "GET", "/people", false)
+}
+
+func BenchmarkGPlusParam(b *testing.B) {
+	benchmarkRoutes(b, googlePlusAPI, "GET", "/people/118051310819094153327", false)
+}
+
+func BenchmarkGPlus2Params(b *testing.B) {
+	benchmarkRoutes(b, googlePlusAPI, "GET", "/people/118051310819094153327/activities/123456789", false)
+}
+
+func BenchmarkGPlusAll(b *testing.B) {
+	benchmarkAll(b, googlePlusAPI, false)
+}
+
+func BenchmarkGPlusAllFrozen(b *testing.B) {
+	benchmarkAll(b, googlePlusAPI, true)
+}
+
+func BenchmarkCatchAll(b *testing.B) {
+	benchmarkRoutes(b, []testRoute{{"GET", "/static/*filepath"}}, "GET", "/static/css/site.css", false)
+}
+
+func BenchmarkGitHub3Params(b *testing.B) {
+	benchmarkRoutes(b, githubAPI, "GET", "/repos/julienschmidt/httprouter/issues/42/labels", false)
+}
+
+func BenchmarkGitHub3ParamsFrozen(b *testing.B) {
+	benchmarkRoutes(b, githubAPI, "GET", "/repos/julienschmidt/httprouter/issues/42/labels", true)
+}
+
+func BenchmarkGitHub3ParamsCached(b *testing.B) {
+	router := loadRouter(githubAPI)
+	router.EnableCache(1024)
+
+	w := &discardWriter{header: make(http.Header)}
+	req := httptest.NewRequest("GET", "/repos/julienschmidt/httprouter/issues/42/labels", nil)
+
+	b.ReportAllocs()
+	b.ResetTimer()
+
+	for i := 0; i < b.N; i++ {
+		router.ServeHTTP(w, req)
+	}
+}
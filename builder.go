@@ -0,0 +1,172 @@
+package hyper
+
+import (
+	"fmt"
+	"net/http"
+	"strings"
+)
+
+// RouteBuilder registers routes that share the same path,
+// so the path is written only once for all of its methods:
+//
+//	router.Route("/users/:id").
+//		Get(showUser).
+//		Put(updateUser).
+//		Delete(deleteUser).
+//		Name("user")
+//
+// Name, Use, Meta and Matcher apply to every route of the builder,
+// including the ones registered before they were called.
+type RouteBuilder struct {
+	router *Router
+	path   string
+
+	name       string
+	middleware []Middleware
+	meta       map[string]interface{}
+	matchers   []Matcher
+
+	routes []*Route
+}
+
+// Route returns a new RouteBuilder for the path.
+func (r *Router) Route(path string) *RouteBuilder {
+	return &RouteBuilder{
+		router: r,
+		path:   path,
+	}
+}
+
+// Path returns the full path of the builder.
+func (b *RouteBuilder) Path() string {
+	return b.path
+}
+
+// Routes returns the routes registered through the builder.
+func (b *RouteBuilder) Routes() []*Route {
+	return b.routes
+}
+
+// Handle registers the handler for the method on the builder path.
+func (b *RouteBuilder) Handle(method string, handler http.Handler) *RouteBuilder {
+	route := b.router.Handle(method, b.path, handler, b.middleware...)
+	route.Name = b.name
+	route.Match(b.matchers...)
+	for key, value := range b.meta {
+		route.WithMeta(key, value)
+	}
+
+	b.routes = append(b.routes, route)
+
+	return b
+}
+
+// Get is a shortcut to the builder.Handle(http.MethodGet, handler) method.
+func (b *RouteBuilder) Get(handler http.Handler) *RouteBuilder {
+	return b.Handle(http.MethodGet, handler)
+}
+
+// Head is a shortcut to the builder.Handle(http.MethodHead, handler) method.
+func (b *RouteBuilder) Head(handler http.Handler) *RouteBuilder {
+	return b.Handle(http.MethodHead, handler)
+}
+
+// Options is a shortcut to the builder.Handle(http.MethodOptions, handler) method.
+func (b *RouteBuilder) Options(handler http.Handler) *RouteBuilder {
+	return b.Handle(http.MethodOptions, handler)
+}
+
+// Post is a shortcut to the builder.Handle(http.MethodPost, handler) method.
+func (b *RouteBuilder) Post(handler http.Handler) *RouteBuilder {
+	return b.Handle(http.MethodPost, handler)
+}
+
+// Put is a shortcut to the builder.Handle(http.MethodPut, handler) method.
+func (b *RouteBuilder) Put(handler http.Handler) *RouteBuilder {
+	return b.Handle(http.MethodPut, handler)
+}
+
+// Patch is a shortcut to the builder.Handle(http.MethodPatch, handler) method.
+func (b *RouteBuilder) Patch(handler http.Handler) *RouteBuilder {
+	return b.Handle(http.MethodPatch, handler)
+}
+
+// Delete is a shortcut to the builder.Handle(http.MethodDelete, handler) method.
+func (b *RouteBuilder) Delete(handler http.Handler) *RouteBuilder {
+	return b.Handle(http.MethodDelete, handler)
+}
+
+// Any is a shortcut to the builder.Handle(MethodAny, handler) method.
+func (b *RouteBuilder) Any(handler http.Handler) *RouteBuilder {
+	return b.Handle(MethodAny, handler)
+}
+
+// Name sets the name of all routes of the builder.
+func (b *RouteBuilder) Name(name string) *RouteBuilder {
+	b.name = name
+	for _, route := range b.routes {
+		route.Named(name)
+	}
+
+	return b
+}
+
+// Use adds middleware to all routes of the builder.
+func (b *RouteBuilder) Use(middleware ...Middleware) *RouteBuilder {
+	b.middleware = append(b.middleware, middleware...)
+	for _, route := range b.routes {
+		route.Use(middleware...)
+	}
+
+	return b
+}
+
+// Meta stores a metadata value under the key on all routes of the builder.
+func (b *RouteBuilder) Meta(key string, value interface{}) *RouteBuilder {
+	if b.meta == nil {
+		b.meta = make(map[string]interface{})
+	}
+
+	b.meta[key] = value
+	for _, route := range b.routes {
+		route.WithMeta(key, value)
+	}
+
+	return b
+}
+
+// Matcher adds matchers to all routes of the builder.
+func (b *RouteBuilder) Matcher(matchers ...Matcher) *RouteBuilder {
+	b.matchers = append(b.matchers, matchers...)
+	for _, route := range b.routes {
+		route.Match(matchers...)
+	}
+
+	return b
+}
+
+// Route creates a nested builder for the subpath and passes it to fn.
+//
+// The nested builder inherits the middleware, metadata and matchers
+// set on the builder up to this point, but not its name.
+func (b *RouteBuilder) Route(subpath string, fn func(b *RouteBuilder)) *RouteBuilder {
+	if subpath == "" || subpath[0] != '/' {
+		panic(fmt.Sprintf("subpath must start with '/' in '%s'", subpath))
+	}
+
+	child := &RouteBuilder{
+		router: b.router,
+		path:   strings.TrimSuffix(b.path, "/") + subpath,
+
+		middleware: append([]Middleware{}, b.middleware...),
+		matchers:   append([]Matcher{}, b.matchers...),
+	}
+
+	for key, value := range b.meta {
+		child.Meta(key, value)
+	}
+
+	fn(child)
+
+	return b
+}
@@ -0,0 +1,94 @@
+package hyper
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestRouteBuilder(t *testing.T) {
+	var calls []string
+
+	trace := func(name string) Middleware {
+		return func(next http.Handler) http.Handler {
+			return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				calls = append(calls, name)
+				next.ServeHTTP(w, r)
+			})
+		}
+	}
+
+	routeName := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		route, _ := RouteFromContext(r.Context())
+		w.Write([]byte(route.Method + " " + route.Name))
+	})
+
+	router := NewRouter()
+	router.Route("/users").
+		Get(routeName).
+		Post(routeName).
+		Use(trace("users")).
+		Name("users").
+		Route("/:id", func(b *RouteBuilder) {
+			b.Get(routeName).
+				Delete(routeName).
+				Use(trace("user")).
+				Meta("owner", "accounts").
+				Name("user")
+		})
+
+	tests := []struct {
+		method, path string
+		body         string
+		calls        int
+	}{
+		{http.MethodGet, "/users", "GET users", 1},
+		{http.MethodPost, "/users", "POST users", 1},
+		{http.MethodGet, "/users/1", "GET user", 2},
+		{http.MethodDelete, "/users/1", "DELETE user", 2},
+	}
+
+	for _, test := range tests {
+		calls = nil
+
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, httptest.NewRequest(test.method, test.path, nil))
+
+		if w.Body.String() != test.body {
+			t.Errorf("%s %s: got body '%s', wanted '%s'", test.method, test.path, w.Body.String(), test.body)
+		}
+
+		if len(calls) != test.calls {
+			t.Errorf("%s %s: got middleware calls %v, wanted %d", test.method, test.path, calls, test.calls)
+		}
+	}
+}
+
+func TestRouteBuilderMatcher(t *testing.T) {
+	router := NewRouter()
+	router.Route("/admin").
+		Get(emptyHandler).
+		Matcher(func(r *http.Request) bool { return r.Header.Get("X-Admin") != "" })
+
+	tests := []struct {
+		header string
+		code   int
+	}{
+		{"", http.StatusNotFound},
+		{"yes", http.StatusOK},
+	}
+
+	for _, test := range tests {
+		req := httptest.NewRequest(http.MethodGet, "/admin", nil)
+		if test.header != "" {
+			req.Header.Set("X-Admin", test.header)
+		}
+
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+
+		if w.Code != test.code {
+			t.Errorf("GET /admin (X-Admin: '%s'): got status %d, wanted %d", test.header, w.Code, test.code)
+		}
+	}
+}
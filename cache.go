@@ -0,0 +1,166 @@
+package hyper
+
+import (
+	"container/list"
+	"context"
+	"sync"
+
+	"github.com/bencicandrej/hyper-router/params"
+)
+
+// CacheStats describes the effectiveness of the lookup cache.
+type CacheStats struct {
+	Hits      uint64
+	Misses    uint64
+	Evictions uint64
+	// Size is the current number of cached lookups.
+	Size int
+}
+
+// EnableCache enables a bounded, least recently used cache of the route
+// lookups, keyed by the method and path of the request. The cache holds
+// the resolved route and params, so the frequently requested URLs skip
+// the tree lookup altogether.
+//
+// Only the lookups that matched a route are cached, and the cache
+// is cleared whenever a route is registered. A size of zero or
+// less disables the cache.
+func (r *Router) EnableCache(size int) {
+	r.cache = nil
+	if size > 0 {
+		r.cache = newLookupCache(size)
+	}
+
+	for _, table := range r.versions {
+		table.EnableCache(size)
+	}
+}
+
+// CacheStats returns the statistics of the lookup cache,
+// including the caches of the version route tables.
+func (r *Router) CacheStats() CacheStats {
+	var stats CacheStats
+	if r.cache != nil {
+		stats = r.cache.stats()
+	}
+
+	for _, table := range r.versions {
+		tableStats := table.CacheStats()
+
+		stats.Hits += tableStats.Hits
+		stats.Misses += tableStats.Misses
+		stats.Evictions += tableStats.Evictions
+		stats.Size += tableStats.Size
+	}
+
+	return stats
+}
+
+type cacheKey struct {
+	method, path string
+}
+
+type cacheEntry struct {
+	key    cacheKey
+	route  *Route
+	params params.Params
+}
+
+type lookupCache struct {
+	mu      sync.Mutex
+	size    int
+	entries map[cacheKey]*list.Element
+	order   *list.List
+
+	hits, misses, evictions uint64
+}
+
+func newLookupCache(size int) *lookupCache {
+	return &lookupCache{
+		size:    size,
+		entries: make(map[cacheKey]*list.Element, size),
+		order:   list.New(),
+	}
+}
+
+// get returns the cached route, and marks the entry as the most recently used.
+func (cache *lookupCache) get(method, path string) (*Route, params.Params, bool) {
+	cache.mu.Lock()
+	defer cache.mu.Unlock()
+
+	element, ok := cache.entries[cacheKey{method, path}]
+	if !ok {
+		cache.misses++
+		return nil, nil, false
+	}
+
+	cache.hits++
+	cache.order.MoveToFront(element)
+	entry := element.Value.(*cacheEntry)
+
+	return entry.route, entry.params, true
+}
+
+// add stores the lookup, evicting the least recently used entry if the cache is full.
+func (cache *lookupCache) add(method, path string, route *Route, ps params.Params) {
+	cache.mu.Lock()
+	defer cache.mu.Unlock()
+
+	key := cacheKey{method, path}
+	if _, ok := cache.entries[key]; ok {
+		return
+	}
+
+	if cache.order.Len() >= cache.size {
+		oldest := cache.order.Back()
+		cache.order.Remove(oldest)
+		delete(cache.entries, oldest.Value.(*cacheEntry).key)
+		cache.evictions++
+	}
+
+	cache.entries[key] = cache.order.PushFront(&cacheEntry{
+		key:    key,
+		route:  route,
+		params: append(params.Params(nil), ps...),
+	})
+}
+
+// purge removes all entries from the cache.
+func (cache *lookupCache) purge() {
+	cache.mu.Lock()
+	defer cache.mu.Unlock()
+
+	cache.entries = make(map[cacheKey]*list.Element, cache.size)
+	cache.order.Init()
+}
+
+func (cache *lookupCache) stats() CacheStats {
+	cache.mu.Lock()
+	defer cache.mu.Unlock()
+
+	return CacheStats{
+		Hits:      cache.hits,
+		Misses:    cache.misses,
+		Evictions: cache.evictions,
+		Size:      cache.order.Len(),
+	}
+}
+
+// getCachedRoute looks up the route through the cache,
+// storing the result of the lookup on a miss.
+func (r *Router) getCachedRoute(ctx context.Context, method, path string) (*Route, context.Context) {
+	if route, ps, ok := r.cache.get(method, path); ok {
+		return route, withParams(ctx, ps)
+	}
+
+	route, routeCtx := r.lookupRoute(ctx, method, path)
+	if route != nil {
+		// Only the params of this lookup are cached, without the
+		// ones of the router this one is mounted under.
+		outer, _ := params.FromContext(ctx)
+		ps, _ := params.FromContext(routeCtx)
+		r.cache.add(method, path, route, ps[len(outer):])
+	}
+
+	return route, routeCtx
+}
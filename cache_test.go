@@ -0,0 +1,53 @@
+package hyper
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/bencicandrej/hyper-router/params"
+)
+
+func TestRouterCache(t *testing.T) {
+	paramValue := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		ps, _ := params.FromContext(r.Context())
+		id, _ := ps.ByName("id")
+		w.Write([]byte(id))
+	})
+
+	router := NewRouter()
+	router.EnableCache(2)
+	router.Get("/users/:id", paramValue)
+
+	requests := []struct {
+		path string
+		want string
+	}{
+		{"/users/1", "1"},
+		{"/users/1", "1"},
+		{"/users/2", "2"},
+		{"/users/3", "3"},
+		{"/users/1", "1"},
+		{"/missing", "404 page not found\n"},
+	}
+
+	for _, request := range requests {
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, httptest.NewRequest(http.MethodGet, request.path, nil))
+
+		if w.Body.String() != request.want {
+			t.Errorf("GET %s: got body '%s', wanted '%s'", request.path, w.Body.String(), request.want)
+		}
+	}
+
+	want := CacheStats{Hits: 1, Misses: 5, Evictions: 2, Size: 2}
+	if stats := router.CacheStats(); stats != want {
+		t.Errorf("router.CacheStats(): got %+v, wanted %+v", stats, want)
+	}
+
+	router.Get("/users/:id/sites", emptyHandler)
+
+	if size := router.CacheStats().Size; size != 0 {
+		t.Errorf("router.CacheStats() after registration: got size %d, wanted 0", size)
+	}
+}
@@ -0,0 +1,319 @@
+package main
+
+import (
+	"bytes"
+	"flag"
+	"fmt"
+	"io"
+	"net/http"
+	"reflect"
+	"sort"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/bencicandrej/hyper-router"
+	"github.com/bencicandrej/hyper-router/config"
+)
+
+// endpoint is a single route of the route files.
+type endpoint struct {
+	config.Route
+
+	// File is the route file declaring the endpoint.
+	File string
+	// Redirect describes the target of the redirect and rewrite rules.
+	Redirect string
+}
+
+func (e endpoint) key() string {
+	return e.Method + " " + e.Pattern
+}
+
+// conflict is an endpoint the router refused to register.
+type conflict struct {
+	endpoint
+	err string
+}
+
+// readEndpoints reads the enabled routes and the redirects of the route files.
+func readEndpoints(files []string) ([]endpoint, error) {
+	if len(files) == 0 {
+		return nil, fmt.Errorf("no route files")
+	}
+
+	var endpoints []endpoint
+	for _, file := range files {
+		cfg, err := config.Read(file)
+		if err != nil {
+			return nil, err
+		}
+
+		for _, route := range cfg.Routes {
+			if !route.Disabled {
+				route.Middleware = append(append([]string{}, cfg.Middleware...), route.Middleware...)
+				endpoints = append(endpoints, endpoint{Route: route, File: file})
+			}
+		}
+
+		for _, rule := range cfg.Redirects {
+			method := rule.Method
+			if method == "" {
+				method = hyper.MethodAny
+			}
+
+			redirect := fmt.Sprintf("-> %s (%d)", rule.To, rule.Code)
+			if rule.Code == 0 {
+				redirect = fmt.Sprintf("-> %s (rewrite)", rule.To)
+			}
+
+			endpoints = append(endpoints, endpoint{
+				Route:    config.Route{Method: method, Pattern: rule.From},
+				File:     file,
+				Redirect: redirect,
+			})
+		}
+	}
+
+	return endpoints, nil
+}
+
+// build registers the endpoints on a router, and returns those
+// the router refused, with the reason of the refusal.
+func build(endpoints []endpoint) (*hyper.Router, []conflict) {
+	r := hyper.NewRouter()
+
+	var conflicts []conflict
+	for _, e := range endpoints {
+		route, err := r.TryHandle(e.Method, e.Pattern, http.NotFoundHandler())
+		if err != nil {
+			conflicts = append(conflicts, conflict{e, err.Error()})
+			continue
+		}
+
+		route.Named(e.Name)
+	}
+
+	return r, conflicts
+}
+
+func sortEndpoints(endpoints []endpoint) {
+	sort.SliceStable(endpoints, func(i, j int) bool {
+		if endpoints[i].Pattern != endpoints[j].Pattern {
+			return endpoints[i].Pattern < endpoints[j].Pattern
+		}
+
+		return endpoints[i].Method < endpoints[j].Method
+	})
+}
+
+func routesCommand(args []string, stdout io.Writer) (int, error) {
+	endpoints, err := readEndpoints(args)
+	if err != nil {
+		return 0, err
+	}
+
+	sortEndpoints(endpoints)
+
+	buff := &bytes.Buffer{}
+
+	w := tabwriter.NewWriter(buff, 0, 4, 2, ' ', 0)
+	fmt.Fprintln(w, "METHOD\tPATTERN\tNAME\tHANDLER\tMIDDLEWARE")
+
+	for _, e := range endpoints {
+		handler := e.Handler
+		if e.Redirect != "" {
+			handler = e.Redirect
+		}
+
+		fmt.Fprintf(w, "%s\t%s\t%s\t%s\t%s\n", e.Method, e.Pattern, e.Name, handler, strings.Join(e.Middleware, ", "))
+	}
+
+	if err := w.Flush(); err != nil {
+		return 0, err
+	}
+
+	// The empty cells of the last columns are padded, trim them.
+	for _, line := range strings.SplitAfter(buff.String(), "\n") {
+		if line != "" {
+			fmt.Fprintln(stdout, strings.TrimRight(line, " \n"))
+		}
+	}
+
+	return 0, nil
+}
+
+func treeCommand(args []string, stdout io.Writer) (int, error) {
+	flags := flag.NewFlagSet("tree", flag.ContinueOnError)
+	flags.SetOutput(io.Discard)
+	format := flags.String("format", "text", "text, dot or json")
+
+	if err := flags.Parse(args); err != nil {
+		return 0, err
+	}
+
+	formats := map[string]hyper.TreeFormat{"text": hyper.TreeText, "dot": hyper.TreeDOT, "json": hyper.TreeJSON}
+	if _, ok := formats[*format]; !ok {
+		return 0, fmt.Errorf("unknown format '%s'", *format)
+	}
+
+	endpoints, err := readEndpoints(flags.Args())
+	if err != nil {
+		return 0, err
+	}
+
+	r, _ := build(endpoints)
+
+	return 0, r.DumpTree(stdout, formats[*format])
+}
+
+func conflictsCommand(args []string, stdout io.Writer) (int, error) {
+	endpoints, err := readEndpoints(args)
+	if err != nil {
+		return 0, err
+	}
+
+	_, conflicts := build(endpoints)
+	for _, c := range conflicts {
+		fmt.Fprintf(stdout, "%s: %s %s: %s\n", c.File, c.Method, c.Pattern, c.err)
+	}
+
+	if len(conflicts) > 0 {
+		return 1, nil
+	}
+
+	return 0, nil
+}
+
+func matchCommand(args []string, stdout io.Writer) (int, error) {
+	flags := flag.NewFlagSet("match", flag.ContinueOnError)
+	flags.SetOutput(io.Discard)
+	method := flags.String("method", http.MethodGet, "the request method")
+
+	if err := flags.Parse(args); err != nil {
+		return 0, err
+	}
+
+	if flags.NArg() < 1 {
+		return 0, fmt.Errorf("no path to match")
+	}
+
+	path := flags.Arg(0)
+
+	endpoints, err := readEndpoints(flags.Args()[1:])
+	if err != nil {
+		return 0, err
+	}
+
+	r, _ := build(endpoints)
+
+	match, ok := r.Lookup(*method, path)
+	if !ok {
+		switch {
+		case len(match.Allowed) > 0:
+			fmt.Fprintf(stdout, "%s %s: method not allowed, allowed: %s\n", *method, path, strings.Join(match.Allowed, ", "))
+		case match.Redirect != "":
+			fmt.Fprintf(stdout, "%s %s: not found, %s would match\n", *method, path, match.Redirect)
+		default:
+			fmt.Fprintf(stdout, "%s %s: not found\n", *method, path)
+		}
+
+		return 1, nil
+	}
+
+	fmt.Fprintf(stdout, "%s %s\n", match.Route.Method, match.Pattern)
+	if match.Name != "" {
+		fmt.Fprintf(stdout, "name: %s\n", match.Name)
+	}
+
+	for _, param := range match.Params {
+		fmt.Fprintf(stdout, "%s: %s\n", param.Key, param.Value)
+	}
+
+	return 0, nil
+}
+
+func diffCommand(args []string, stdout io.Writer) (int, error) {
+	if len(args) != 2 {
+		return 0, fmt.Errorf("diff needs the old and the new route file")
+	}
+
+	before, err := readEndpoints(args[:1])
+	if err != nil {
+		return 0, err
+	}
+
+	after, err := readEndpoints(args[1:])
+	if err != nil {
+		return 0, err
+	}
+
+	// The first endpoint of the same method and pattern is the registered one.
+	old := make(map[string]endpoint)
+	for _, e := range before {
+		if _, ok := old[e.key()]; !ok {
+			old[e.key()] = e
+		}
+	}
+
+	var lines []string
+
+	sortEndpoints(after)
+	seen := make(map[string]bool)
+	for _, e := range after {
+		if seen[e.key()] {
+			continue
+		}
+
+		seen[e.key()] = true
+
+		previous, ok := old[e.key()]
+		if !ok {
+			lines = append(lines, "+ "+e.key())
+			continue
+		}
+
+		for _, change := range changes(previous, e) {
+			lines = append(lines, "~ "+e.key()+": "+change)
+		}
+	}
+
+	sortEndpoints(before)
+	for _, e := range before {
+		if !seen[e.key()] {
+			seen[e.key()] = true
+			lines = append(lines, "- "+e.key())
+		}
+	}
+
+	for _, line := range lines {
+		fmt.Fprintln(stdout, line)
+	}
+
+	if len(lines) > 0 {
+		return 1, nil
+	}
+
+	return 0, nil
+}
+
+// changes describes the differences of the same endpoint in two route sets.
+func changes(before, after endpoint) []string {
+	var changes []string
+
+	describe := func(field, old, new string) {
+		if old != new {
+			changes = append(changes, fmt.Sprintf("%s %q -> %q", field, old, new))
+		}
+	}
+
+	describe("handler", before.Handler, after.Handler)
+	describe("redirect", before.Redirect, after.Redirect)
+	describe("name", before.Name, after.Name)
+	describe("middleware", strings.Join(before.Middleware, ", "), strings.Join(after.Middleware, ", "))
+
+	if !reflect.DeepEqual(before.Meta, after.Meta) {
+		changes = append(changes, fmt.Sprintf("meta %v -> %v", before.Meta, after.Meta))
+	}
+
+	return changes
+}
@@ -0,0 +1,56 @@
+// Command hyper inspects route files, in the format of the config package.
+//
+// Usage:
+//
+//	hyper routes FILE...                         print the sorted route table
+//	hyper tree [-format text|dot|json] FILE...   render the route tree
+//	hyper conflicts FILE...                      report the routes that can not be registered
+//	hyper match [-method GET] PATH FILE...       show the route matching the path
+//	hyper diff OLD NEW                           show the added, removed and changed endpoints
+//
+// The conflicts and diff commands exit with the status 1 when they
+// find any conflicts or differences, so they can fail a CI check.
+package main
+
+import (
+	"fmt"
+	"io"
+	"os"
+)
+
+const usage = `usage:
+  hyper routes FILE...
+  hyper tree [-format text|dot|json] FILE...
+  hyper conflicts FILE...
+  hyper match [-method GET] PATH FILE...
+  hyper diff OLD NEW
+`
+
+// commands maps the command names to their implementations, which
+// return the exit status of the command.
+var commands = map[string]func(args []string, stdout io.Writer) (int, error){
+	"routes":    routesCommand,
+	"tree":      treeCommand,
+	"conflicts": conflictsCommand,
+	"match":     matchCommand,
+	"diff":      diffCommand,
+}
+
+func main() {
+	os.Exit(run(os.Args[1:], os.Stdout, os.Stderr))
+}
+
+func run(args []string, stdout, stderr io.Writer) int {
+	if len(args) == 0 || commands[args[0]] == nil {
+		fmt.Fprint(stderr, usage)
+		return 2
+	}
+
+	status, err := commands[args[0]](args[1:], stdout)
+	if err != nil {
+		fmt.Fprintf(stderr, "hyper %s: %s\n", args[0], err)
+		return 2
+	}
+
+	return status
+}
@@ -0,0 +1,143 @@
+package main
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+const oldRoutes = `
+routes:
+  - {method: GET, pattern: /users, handler: listUsers}
+  - {method: GET, pattern: /users/:id, handler: showUser, name: users.show}
+  - {method: DELETE, pattern: /users/:id, handler: deleteUser}
+redirects:
+  - {from: /people/:id, to: /users/:id, code: 301}
+`
+
+const newRoutes = `
+middleware: [logger]
+routes:
+  - {method: GET, pattern: /users, handler: listUsers}
+  - {method: GET, pattern: /users/:id, handler: findUser, name: users.show}
+  - {method: POST, pattern: /users, handler: createUser}
+  - {method: GET, pattern: /users/:user_id/sites, handler: listSites}
+  - {method: GET, pattern: /users/:id, handler: showUser}
+`
+
+func writeRoutes(t *testing.T, name, content string) string {
+	file := filepath.Join(t.TempDir(), name)
+	if err := os.WriteFile(file, []byte(content), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	return file
+}
+
+func runCommand(args ...string) (int, string, string) {
+	stdout, stderr := &bytes.Buffer{}, &bytes.Buffer{}
+	status := run(args, stdout, stderr)
+
+	return status, stdout.String(), stderr.String()
+}
+
+func TestRoutes(t *testing.T) {
+	file := writeRoutes(t, "routes.yaml", oldRoutes)
+
+	status, stdout, _ := runCommand("routes", file)
+
+	expected := `METHOD  PATTERN      NAME        HANDLER              MIDDLEWARE
+*       /people/:id              -> /users/:id (301)
+GET     /users                   listUsers
+DELETE  /users/:id               deleteUser
+GET     /users/:id   users.show  showUser
+`
+
+	if status != 0 || stdout != expected {
+		t.Errorf("routes: got status %d and\n%s\nwanted\n%s", status, stdout, expected)
+	}
+}
+
+func TestTree(t *testing.T) {
+	file := writeRoutes(t, "routes.yaml", oldRoutes)
+
+	status, stdout, _ := runCommand("tree", "-format", "dot", file)
+	if status != 0 || !strings.HasPrefix(stdout, "digraph") {
+		t.Errorf("tree: got status %d and\n%s", status, stdout)
+	}
+
+	if status, _, stderr := runCommand("tree", "-format", "svg", file); status != 2 || !strings.Contains(stderr, "unknown format 'svg'") {
+		t.Errorf("tree: got status %d and %s", status, stderr)
+	}
+}
+
+func TestConflicts(t *testing.T) {
+	file := writeRoutes(t, "routes.yaml", newRoutes)
+
+	status, stdout, _ := runCommand("conflicts", file)
+
+	expected := file + ": GET /users/:user_id/sites: handler for route '/users/:user_id/sites' already exists\n" +
+		file + ": GET /users/:id: handler for route '/users/:id' already exists\n"
+
+	if status != 1 || stdout != expected {
+		t.Errorf("conflicts: got status %d and\n%s\nwanted\n%s", status, stdout, expected)
+	}
+
+	if status, stdout, _ := runCommand("conflicts", writeRoutes(t, "old.yaml", oldRoutes)); status != 0 || stdout != "" {
+		t.Errorf("conflicts: got status %d and\n%s", status, stdout)
+	}
+}
+
+func TestMatch(t *testing.T) {
+	file := writeRoutes(t, "routes.yaml", oldRoutes)
+
+	tests := []struct {
+		args   []string
+		status int
+		stdout string
+	}{
+		{[]string{"/users/42"}, 0, "GET /users/:id\nname: users.show\nid: 42\n"},
+		{[]string{"-method", "PUT", "/users/42"}, 1, "PUT /users/42: method not allowed, allowed: DELETE, GET\n"},
+		{[]string{"/users/"}, 1, "GET /users/: not found, /users would match\n"},
+		{[]string{"/missing"}, 1, "GET /missing: not found\n"},
+	}
+
+	for _, test := range tests {
+		status, stdout, _ := runCommand(append(append([]string{"match"}, test.args...), file)...)
+		if status != test.status || stdout != test.stdout {
+			t.Errorf("match %v: got status %d and %q, wanted %d and %q", test.args, status, stdout, test.status, test.stdout)
+		}
+	}
+}
+
+func TestDiff(t *testing.T) {
+	before := writeRoutes(t, "old.yaml", oldRoutes)
+	after := writeRoutes(t, "new.yaml", newRoutes)
+
+	status, stdout, _ := runCommand("diff", before, after)
+
+	expected := `~ GET /users: middleware "" -> "logger"
++ POST /users
+~ GET /users/:id: handler "showUser" -> "findUser"
+~ GET /users/:id: middleware "" -> "logger"
++ GET /users/:user_id/sites
+- * /people/:id
+- DELETE /users/:id
+`
+
+	if status != 1 || stdout != expected {
+		t.Errorf("diff: got status %d and\n%s\nwanted\n%s", status, stdout, expected)
+	}
+
+	if status, stdout, _ := runCommand("diff", before, before); status != 0 || stdout != "" {
+		t.Errorf("diff: got status %d and\n%s", status, stdout)
+	}
+}
+
+func TestUsage(t *testing.T) {
+	if status, _, stderr := runCommand("unknown"); status != 2 || !strings.HasPrefix(stderr, "usage:") {
+		t.Errorf("run: got status %d and %s", status, stderr)
+	}
+}
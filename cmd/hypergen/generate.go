@@ -0,0 +1,273 @@
+package main
+
+import (
+	"bytes"
+	"fmt"
+	"go/format"
+	"go/token"
+	"strings"
+	"text/template"
+	"unicode"
+
+	"github.com/bencicandrej/hyper-router/config"
+	"github.com/bencicandrej/hyper-router/internal/inflect"
+)
+
+// builder is a single generated pattern constant and URL function.
+type builder struct {
+	Name    string
+	Pattern string
+	Params  []param
+	// Parts are the Go expressions joined into the URL.
+	Parts []string
+}
+
+type param struct {
+	Name     string
+	Variable string
+}
+
+// generate returns the formatted Go source of the URL builders.
+func generate(cfg *config.Config, pkg, source string) ([]byte, error) {
+	var builders []*builder
+	byPattern := make(map[string]*builder)
+	byName := make(map[string]string)
+
+	for _, route := range cfg.Routes {
+		if route.Disabled {
+			continue
+		}
+
+		if b, ok := byPattern[route.Pattern]; ok {
+			// The first named route of the pattern names the builder.
+			if route.Name == "" || b.Name != patternName(route.Pattern) {
+				continue
+			}
+
+			delete(byName, b.Name)
+			b.Name = identifier(route.Name)
+		} else {
+			b, err := newBuilder(route)
+			if err != nil {
+				return nil, err
+			}
+
+			byPattern[route.Pattern] = b
+			builders = append(builders, b)
+		}
+
+		b := byPattern[route.Pattern]
+		if other, ok := byName[b.Name]; ok {
+			return nil, fmt.Errorf("patterns '%s' and '%s' both generate the name %s, name one of the routes", other, b.Pattern, b.Name)
+		}
+
+		byName[b.Name] = b.Pattern
+	}
+
+	buff := &bytes.Buffer{}
+	err := sourceTemplate.Execute(buff, struct {
+		Package  string
+		Source   string
+		Builders []*builder
+		Wildcard bool
+		Escape   bool
+	}{pkg, source, builders, usesWildcard(builders), usesParams(builders)})
+	if err != nil {
+		return nil, err
+	}
+
+	return format.Source(buff.Bytes())
+}
+
+func newBuilder(route config.Route) (*builder, error) {
+	if route.Pattern == "" || route.Pattern[0] != '/' {
+		return nil, fmt.Errorf("pattern '%s' must start with '/'", route.Pattern)
+	}
+
+	b := &builder{
+		Name:    patternName(route.Pattern),
+		Pattern: route.Pattern,
+	}
+
+	if route.Name != "" {
+		b.Name = identifier(route.Name)
+	}
+
+	if b.Name == "" {
+		return nil, fmt.Errorf("can not generate a name for the route '%s'", route.Name)
+	}
+
+	variables := make(map[string]bool)
+
+	static := ""
+	for _, segment := range strings.Split(route.Pattern, "/")[1:] {
+		if segment == "" || (segment[0] != ':' && segment[0] != '*') {
+			static += "/" + segment
+			continue
+		}
+
+		p := param{Name: segment[1:], Variable: variable(segment[1:])}
+		if variables[p.Variable] {
+			return nil, fmt.Errorf("pattern '%s' has two params named %s", route.Pattern, p.Variable)
+		}
+
+		variables[p.Variable] = true
+		b.Params = append(b.Params, p)
+
+		b.Parts = append(b.Parts, fmt.Sprintf("%q", static+"/"))
+		static = ""
+
+		if segment[0] == '*' {
+			b.Parts = append(b.Parts, "escapeWildcard("+p.Variable+")")
+		} else {
+			b.Parts = append(b.Parts, "escapeParam("+p.Variable+")")
+		}
+	}
+
+	if static != "" || len(b.Parts) == 0 {
+		b.Parts = append(b.Parts, fmt.Sprintf("%q", static))
+	}
+
+	return b, nil
+}
+
+// patternName derives the name from the static segments of the pattern,
+// where the segments followed by a param are singular, the same as the
+// params of router.Resource, e.g. UserSites for /users/:id/sites/*url
+// and CategoryItems for /categories/:id/items.
+func patternName(pattern string) string {
+	segments := strings.Split(pattern, "/")
+
+	name := ""
+	for i, segment := range segments {
+		if segment == "" || segment[0] == ':' || segment[0] == '*' {
+			continue
+		}
+
+		if i+1 < len(segments) && segments[i+1] != "" && segments[i+1][0] == ':' {
+			segment = inflect.Singular(segment)
+		}
+
+		name += identifier(segment)
+	}
+
+	if name == "" {
+		return "Root"
+	}
+
+	return name
+}
+
+// identifier converts the name into an exported Go identifier,
+// e.g. users.show to UsersShow.
+func identifier(name string) string {
+	words := strings.FieldsFunc(name, func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+	})
+
+	result := ""
+	for _, word := range words {
+		runes := []rune(word)
+		runes[0] = unicode.ToUpper(runes[0])
+		result += string(runes)
+	}
+
+	if result != "" && unicode.IsDigit([]rune(result)[0]) {
+		result = "R" + result
+	}
+
+	return result
+}
+
+// variable converts the param name into a Go variable name,
+// e.g. user_id to userID.
+func variable(name string) string {
+	words := strings.FieldsFunc(name, func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+	})
+
+	result := ""
+	for i, word := range words {
+		switch {
+		case i == 0:
+			result += strings.ToLower(word[:1]) + word[1:]
+		case strings.ToLower(word) == "id" || strings.ToLower(word) == "url":
+			result += strings.ToUpper(word)
+		default:
+			result += strings.ToUpper(word[:1]) + word[1:]
+		}
+	}
+
+	// The builders call the escape helpers, so the params must not shadow them.
+	if result == "" || token.IsKeyword(result) || unicode.IsDigit([]rune(result)[0]) ||
+		result == "escapeParam" || result == "escapeWildcard" {
+		result = "p" + identifier(result)
+	}
+
+	return result
+}
+
+func usesWildcard(builders []*builder) bool {
+	for _, b := range builders {
+		for _, part := range b.Parts {
+			if strings.HasPrefix(part, "escapeWildcard(") {
+				return true
+			}
+		}
+	}
+
+	return false
+}
+
+func usesParams(builders []*builder) bool {
+	for _, b := range builders {
+		if len(b.Params) > 0 {
+			return true
+		}
+	}
+
+	return false
+}
+
+var sourceTemplate = template.Must(template.New("source").Parse(`// Code generated by hypergen from {{.Source}}; DO NOT EDIT.
+
+package {{.Package}}
+{{if .Escape}}
+import (
+	"net/url"
+{{- if .Wildcard}}
+	"strings"
+{{- end}}
+)
+{{end}}
+// Route patterns.
+const (
+{{- range .Builders}}
+	Pattern{{.Name}} = {{printf "%q" .Pattern}}
+{{- end}}
+)
+{{range .Builders}}
+// URL{{.Name}} builds the path of the {{.Pattern}} route.
+func URL{{.Name}}({{range $i, $p := .Params}}{{if $i}}, {{end}}{{$p.Variable}} string{{end}}) string {
+	return {{range $i, $part := .Parts}}{{if $i}} + {{end}}{{$part}}{{end}}
+}
+{{end}}
+{{- if .Escape}}
+// escapeParam escapes the param value, so it stays a single path segment.
+func escapeParam(value string) string {
+	return url.PathEscape(value)
+}
+{{- end}}
+{{- if .Wildcard}}
+
+// escapeWildcard escapes the segments of the wildcard value, keeping the '/'.
+func escapeWildcard(value string) string {
+	segments := strings.Split(value, "/")
+	for i, segment := range segments {
+		segments[i] = url.PathEscape(segment)
+	}
+
+	return strings.Join(segments, "/")
+}
+{{- end}}
+`))
@@ -0,0 +1,101 @@
+package main
+
+import (
+	"go/ast"
+	"go/importer"
+	"go/parser"
+	"go/token"
+	"go/types"
+	"strings"
+	"testing"
+
+	"github.com/bencicandrej/hyper-router/config"
+)
+
+const testRoutes = `
+routes:
+  - {method: GET, pattern: /, handler: home}
+  - {method: GET, pattern: /users, handler: listUsers}
+  - {method: GET, pattern: /users/:id, handler: showUser}
+  - {method: PUT, pattern: /users/:id, handler: updateUser, name: users.update}
+  - {method: GET, pattern: /users/:id/sites/*url, handler: showSite}
+  - {method: GET, pattern: /orgs/:org_id/repos/:type, handler: listRepos}
+  - {method: GET, pattern: /old, handler: old, disabled: true}
+`
+
+func TestGenerate(t *testing.T) {
+	cfg, err := config.Parse([]byte(testRoutes))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	code, err := generate(cfg, "routes", "routes.yaml")
+	if err != nil {
+		t.Fatalf("generate(): got error %s", err)
+	}
+
+	source := string(code)
+
+	for _, expected := range []string{
+		"// Code generated by hypergen from routes.yaml; DO NOT EDIT.",
+		"func URLRoot() string {\n\treturn \"/\"\n}",
+		`PatternUsersUpdate = "/users/:id"`,
+		"func URLUsers() string {\n\treturn \"/users\"\n}",
+		"func URLUsersUpdate(id string) string {\n\treturn \"/users/\" + escapeParam(id)\n}",
+		"func URLUserSites(id string, url string) string {\n\treturn \"/users/\" + escapeParam(id) + \"/sites/\" + escapeWildcard(url)\n}",
+		"func URLOrgRepo(orgID string, pType string) string",
+	} {
+		if !strings.Contains(source, expected) {
+			t.Errorf("generate(): missing %q in\n%s", expected, source)
+		}
+	}
+
+	if strings.Contains(source, "/old") {
+		t.Errorf("generate(): generated a disabled route")
+	}
+
+	fset := token.NewFileSet()
+	file, err := parser.ParseFile(fset, "routes_gen.go", code, 0)
+	if err != nil {
+		t.Fatalf("generate(): got invalid Go source %s", err)
+	}
+
+	conf := types.Config{Importer: importer.ForCompiler(fset, "source", nil)}
+	if _, err := conf.Check("routes", fset, []*ast.File{file}, nil); err != nil {
+		t.Errorf("generate(): got type error %s\n%s", err, source)
+	}
+}
+
+func TestGenerateConflict(t *testing.T) {
+	cfg, err := config.Parse([]byte(`
+routes:
+  - {method: GET, pattern: /users/:id, handler: showUser}
+  - {method: GET, pattern: /user/:id, handler: showUser}
+`))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := generate(cfg, "routes", "routes.yaml"); err == nil || !strings.Contains(err.Error(), "both generate the name User") {
+		t.Errorf("generate(): got error %v", err)
+	}
+}
+
+func TestPatternName(t *testing.T) {
+	tests := []struct {
+		pattern, want string
+	}{
+		{"/", "Root"},
+		{"/users/:id/sites/*url", "UserSites"},
+		{"/categories/:id", "Category"},
+		{"/boxes/:id/matches/:match_id", "BoxMatch"},
+		{"/addresses/:id", "Address"},
+		{"/news", "News"},
+	}
+
+	for _, test := range tests {
+		if got := patternName(test.pattern); got != test.want {
+			t.Errorf("patternName('%s'): got %s, wanted %s", test.pattern, got, test.want)
+		}
+	}
+}
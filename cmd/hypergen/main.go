@@ -0,0 +1,59 @@
+// Command hypergen generates typed URL builders for the routes of
+// a route file, in the format of the config package.
+//
+// For every pattern, it generates a constant holding the pattern and
+// a function building the URL from the params, e.g. for the route
+// /users/:id/sites/*url:
+//
+//	const PatternUserSites = "/users/:id/sites/*url"
+//
+//	func URLUserSites(id string, url string) string
+//
+// The names are taken from the route names, e.g. users.show becomes
+// UsersShow, or from the static segments of the pattern, where the
+// segments followed by a param are singular. When a route changes,
+// the callers of its builder fail to compile instead of producing
+// broken links.
+//
+// Usage, in a file of the package the builders are generated for:
+//
+//	//go:generate hypergen -routes routes.yaml -o routes_gen.go
+package main
+
+import (
+	"flag"
+	"fmt"
+	"os"
+
+	"github.com/bencicandrej/hyper-router/config"
+)
+
+func main() {
+	routes := flag.String("routes", "routes.yaml", "the route file, in YAML or JSON")
+	output := flag.String("o", "routes_gen.go", "the output file")
+	pkg := flag.String("package", os.Getenv("GOPACKAGE"), "the package name, defaults to the package running go:generate")
+	flag.Parse()
+
+	if err := run(*routes, *output, *pkg); err != nil {
+		fmt.Fprintln(os.Stderr, "hypergen:", err)
+		os.Exit(1)
+	}
+}
+
+func run(routes, output, pkg string) error {
+	if pkg == "" {
+		return fmt.Errorf("package name is required outside of go:generate, use -package")
+	}
+
+	cfg, err := config.Read(routes)
+	if err != nil {
+		return err
+	}
+
+	code, err := generate(cfg, pkg, routes)
+	if err != nil {
+		return err
+	}
+
+	return os.WriteFile(output, code, 0644)
+}
@@ -0,0 +1,114 @@
+//go:build compare
+
+package hyper
+
+// The benchmarks below run the same route sets and requests through
+// httprouter, so the numbers can be compared with the ones of the
+// benchmarks of this package. They are built only with the compare
+// tag, so httprouter is not a dependency of the regular tests:
+//
+//	go test -tags compare -run none -bench .
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/julienschmidt/httprouter"
+)
+
+func httpRouterHandle(http.ResponseWriter, *http.Request, httprouter.Params) {}
+
+func loadHTTPRouter(routes []testRoute) *httprouter.Router {
+	router := httprouter.New()
+	for _, route := range routes {
+		router.Handle(route.method, route.path, httpRouterHandle)
+	}
+
+	return router
+}
+
+func benchmarkHTTPRouterRoutes(b *testing.B, routes []testRoute, method, path string) {
+	router := loadHTTPRouter(routes)
+
+	w := &discardWriter{header: make(http.Header)}
+	req := httptest.NewRequest(method, path, nil)
+
+	b.ReportAllocs()
+	b.ResetTimer()
+
+	for i := 0; i < b.N; i++ {
+		router.ServeHTTP(w, req)
+	}
+}
+
+func benchmarkHTTPRouterAll(b *testing.B, routes []testRoute) {
+	router := loadHTTPRouter(routes)
+
+	w := &discardWriter{header: make(http.Header)}
+	requests := make([]*http.Request, len(routes))
+	for i, route := range routes {
+		requests[i] = httptest.NewRequest(route.method, requestPath(route.path), nil)
+	}
+
+	b.ReportAllocs()
+	b.ResetTimer()
+
+	for i := 0; i < b.N; i++ {
+		for _, req := range requests {
+			router.ServeHTTP(w, req)
+		}
+	}
+}
+
+func BenchmarkHTTPRouterGitHubStatic(b *testing.B) {
+	benchmarkHTTPRouterRoutes(b, githubAPI, "GET", "/user/repos")
+}
+
+func BenchmarkHTTPRouterGitHubParam(b *testing.B) {
+	benchmarkHTTPRouterRoutes(b, githubAPI, "GET", "/repos/julienschmidt/httprouter/stargazers")
+}
+
+func BenchmarkHTTPRouterGitHub3Params(b *testing.B) {
+	benchmarkHTTPRouterRoutes(b, githubAPI, "GET", "/repos/julienschmidt/httprouter/issues/42/labels")
+}
+
+func BenchmarkHTTPRouterGitHubAll(b *testing.B) {
+	benchmarkHTTPRouterAll(b, githubAPI)
+}
+
+func BenchmarkHTTPRouterParseStatic(b *testing.B) {
+	benchmarkHTTPRouterRoutes(b, parseAPI, "GET", "/1/users")
+}
+
+func BenchmarkHTTPRouterParse1Param(b *testing.B) {
+	benchmarkHTTPRouterRoutes(b, parseAPI, "GET", "/1/classes/go")
+}
+
+func BenchmarkHTTPRouterParse2Params(b *testing.B) {
+	benchmarkHTTPRouterRoutes(b, parseAPI, "GET", "/1/classes/go/123456789")
+}
+
+func BenchmarkHTTPRouterParseAll(b *testing.B) {
+	benchmarkHTTPRouterAll(b, parseAPI)
+}
+
+func BenchmarkHTTPRouterGPlusStatic(b *testing.B) {
+	benchmarkHTTPRouterRoutes(b, googlePlusAPI, "GET", "/people")
+}
+
+func BenchmarkHTTPRouterGPlusParam(b *testing.B) {
+	benchmarkHTTPRouterRoutes(b, googlePlusAPI, "GET", "/people/118051310819094153327")
+}
+
+func BenchmarkHTTPRouterGPlus2Params(b *testing.B) {
+	benchmarkHTTPRouterRoutes(b, googlePlusAPI, "GET", "/people/118051310819094153327/activities/123456789")
+}
+
+func BenchmarkHTTPRouterGPlusAll(b *testing.B) {
+	benchmarkHTTPRouterAll(b, googlePlusAPI)
+}
+
+func BenchmarkHTTPRouterCatchAll(b *testing.B) {
+	benchmarkHTTPRouterRoutes(b, []testRoute{{"GET", "/static/*filepath"}}, "GET", "/static/css/site.css")
+}
@@ -0,0 +1,436 @@
+// Package config registers the routes declared in a YAML or JSON file,
+// resolving the handler and middleware names against a Registry.
+//
+// A configuration file looks like:
+//
+//	middleware: [logger]
+//	routes:
+//	  - method: GET
+//	    pattern: /users/:id
+//	    handler: showUser
+//	    middleware: [auth]
+//	    name: users.show
+//	    meta:
+//	      owner: accounts
+//	  - method: DELETE
+//	    pattern: /users/:id
+//	    handler: deleteUser
+//	    disabled: true
+//	redirects:
+//	  - from: /people/:id
+//	    to: /users/:id
+//	    code: 301
+package config
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"os"
+	"sort"
+	"strings"
+
+	"github.com/bencicandrej/hyper-router"
+)
+
+// Config is the route configuration.
+type Config struct {
+	// Middleware is applied to all routes, before the route middleware.
+	Middleware []string `json:"middleware,omitempty"`
+	Routes     []Route  `json:"routes"`
+	// Redirects are the redirect and rewrite rules, see hyper.Rule.
+	Redirects []hyper.Rule `json:"redirects,omitempty"`
+
+	file  string
+	lines map[string]int
+}
+
+// Route is a single route of the configuration.
+type Route struct {
+	// Method is the method of the route, or * for every method.
+	Method     string                 `json:"method"`
+	Pattern    string                 `json:"pattern"`
+	Handler    string                 `json:"handler"`
+	Middleware []string               `json:"middleware,omitempty"`
+	Name       string                 `json:"name,omitempty"`
+	Meta       map[string]interface{} `json:"meta,omitempty"`
+	// Disabled routes are not registered.
+	Disabled bool `json:"disabled,omitempty"`
+}
+
+// Registry holds the handlers and middleware the configuration refers to by name.
+type Registry struct {
+	handlers   map[string]http.Handler
+	middleware map[string]hyper.Middleware
+}
+
+// NewRegistry returns an empty Registry.
+func NewRegistry() *Registry {
+	return &Registry{
+		handlers:   make(map[string]http.Handler),
+		middleware: make(map[string]hyper.Middleware),
+	}
+}
+
+// Handler registers the handler under the name, and panics
+// if a handler with the same name is already registered.
+func (reg *Registry) Handler(name string, handler http.Handler) *Registry {
+	if _, ok := reg.handlers[name]; ok {
+		panic(fmt.Sprintf("handler '%s' is already registered", name))
+	}
+
+	reg.handlers[name] = handler
+
+	return reg
+}
+
+// HandlerFunc is a shortcut to the registry.Handler(name, http.HandlerFunc(handler)) method.
+func (reg *Registry) HandlerFunc(name string, handler http.HandlerFunc) *Registry {
+	return reg.Handler(name, handler)
+}
+
+// Middleware registers the middleware under the name, and panics
+// if a middleware with the same name is already registered.
+func (reg *Registry) Middleware(name string, middleware hyper.Middleware) *Registry {
+	if _, ok := reg.middleware[name]; ok {
+		panic(fmt.Sprintf("middleware '%s' is already registered", name))
+	}
+
+	reg.middleware[name] = middleware
+
+	return reg
+}
+
+// Error is a problem of a single entry of the configuration.
+type Error struct {
+	File string
+	// Entry is the location of the entry, e.g. routes[2].
+	Entry string
+	// Line is the line of the entry in the file, or zero if unknown.
+	Line    int
+	Message string
+}
+
+func (err *Error) Error() string {
+	location := err.Entry
+	if err.Line > 0 {
+		location = fmt.Sprintf("%s (line %d)", err.Entry, err.Line)
+	}
+
+	if err.File != "" {
+		location = err.File + ": " + location
+	}
+
+	return location + ": " + err.Message
+}
+
+// Errors is the list of all problems found in the configuration.
+type Errors []*Error
+
+func (errs Errors) Error() string {
+	messages := make([]string, len(errs))
+	for i, err := range errs {
+		messages[i] = err.Error()
+	}
+
+	return strings.Join(messages, "\n")
+}
+
+// Load reads the configuration file and registers its routes on the router.
+func Load(file string, r *hyper.Router, reg *Registry) error {
+	config, err := Read(file)
+	if err != nil {
+		return err
+	}
+
+	return config.Apply(r, reg)
+}
+
+// Read reads the YAML or JSON configuration file.
+func Read(file string) (*Config, error) {
+	data, err := os.ReadFile(file)
+	if err != nil {
+		return nil, err
+	}
+
+	config, err := Parse(data)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %s", file, err)
+	}
+
+	config.file = file
+
+	return config, nil
+}
+
+// Parse parses the YAML or JSON configuration. Unknown
+// fields are reported as errors, to catch the typos.
+func Parse(data []byte) (*Config, error) {
+	root, err := parseYAML(data)
+	if err != nil {
+		return nil, err
+	}
+
+	config := &Config{lines: make(map[string]int)}
+	if root == nil {
+		return config, nil
+	}
+
+	if root.kind != mappingNode {
+		return nil, &yamlError{root.line, "configuration must be a mapping"}
+	}
+
+	for i := 0; i < len(root.content); i += 2 {
+		key, value := root.content[i], root.content[i+1]
+
+		switch key.value {
+		case "middleware":
+			err = value.decode(&config.Middleware)
+		case "routes":
+			var entries []*yamlNode
+			if entries, err = config.entries(key.value, value); err == nil {
+				config.Routes = make([]Route, len(entries))
+				err = decodeEntries(entries, func(i int) interface{} { return &config.Routes[i] })
+			}
+		case "redirects":
+			var entries []*yamlNode
+			if entries, err = config.entries(key.value, value); err == nil {
+				config.Redirects = make([]hyper.Rule, len(entries))
+				err = decodeEntries(entries, func(i int) interface{} { return &config.Redirects[i] })
+			}
+		default:
+			err = &yamlError{key.line, fmt.Sprintf("field %s not found in type config.Config", key.value)}
+		}
+
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	return config, nil
+}
+
+// entries returns the entries of the list of the key,
+// recording their lines for the errors of the entries.
+func (config *Config) entries(key string, list *yamlNode) ([]*yamlNode, error) {
+	if list.kind == scalarNode && list.decoded() == nil {
+		return nil, nil
+	}
+
+	if list.kind != sequenceNode {
+		return nil, &yamlError{list.line, key + " must be a list"}
+	}
+
+	for i, entry := range list.content {
+		config.lines[fmt.Sprintf("%s[%d]", key, i)] = entry.line
+	}
+
+	return list.content, nil
+}
+
+// decodeEntries decodes the entries one by one, so the
+// errors are reported with the lines of their own entry.
+func decodeEntries(entries []*yamlNode, value func(i int) interface{}) error {
+	for i, entry := range entries {
+		if err := entry.decode(value(i)); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// Apply resolves the names of the configuration against the registry,
+// and registers the routes and redirects on the router.
+//
+// Apply returns the Errors describing every unknown name, invalid entry
+// or conflict, and registers nothing if any entry can not be registered.
+func (config *Config) Apply(r *hyper.Router, reg *Registry) error {
+	var errs Errors
+
+	shared, problems := reg.resolveMiddleware(config.Middleware)
+	for _, problem := range problems {
+		errs = append(errs, config.error("middleware", problem))
+	}
+
+	type resolved struct {
+		route      Route
+		entry      string
+		handler    http.Handler
+		middleware []hyper.Middleware
+	}
+
+	var routes []resolved
+	for i, route := range config.Routes {
+		entry := fmt.Sprintf("routes[%d]", i)
+
+		if route.Method == "" || route.Pattern == "" {
+			errs = append(errs, config.error(entry, "method and pattern are required"))
+			continue
+		}
+
+		handler, ok := reg.handlers[route.Handler]
+		if !ok {
+			errs = append(errs, config.error(entry, unknownName("handler", route.Handler, reg.handlerNames())))
+		}
+
+		middleware, problems := reg.resolveMiddleware(route.Middleware)
+		for _, problem := range problems {
+			errs = append(errs, config.error(entry, problem))
+		}
+
+		if ok && len(problems) == 0 && !route.Disabled {
+			routes = append(routes, resolved{route, entry, handler, append(append([]hyper.Middleware{}, shared...), middleware...)})
+		}
+	}
+
+	if len(errs) > 0 {
+		return errs
+	}
+
+	// The routes are registered on a scratch router with the routes of
+	// the router first, so a conflict does not leave the router half filled.
+	scratch := hyper.NewRouter()
+	for _, route := range r.Routes() {
+		scratch.TryHandle(route.Method, route.Pattern, http.NotFoundHandler())
+	}
+
+	for _, route := range routes {
+		if _, err := scratch.TryHandle(route.route.Method, route.route.Pattern, route.handler); err != nil {
+			errs = append(errs, config.error(route.entry, err.Error()))
+		}
+	}
+
+	for i, rule := range config.Redirects {
+		if err := scratch.LoadRules([]hyper.Rule{rule}); err != nil {
+			errs = append(errs, config.error(fmt.Sprintf("redirects[%d]", i), errors.Unwrap(err).Error()))
+		}
+	}
+
+	if len(errs) > 0 {
+		return errs
+	}
+
+	for _, route := range routes {
+		registered, err := r.TryHandle(route.route.Method, route.route.Pattern, route.handler, route.middleware...)
+		if err != nil {
+			return Errors{config.error(route.entry, err.Error())}
+		}
+
+		if route.route.Name != "" {
+			registered.Named(route.route.Name)
+		}
+
+		for key, value := range route.route.Meta {
+			registered.WithMeta(key, value)
+		}
+	}
+
+	return r.LoadRules(config.Redirects)
+}
+
+func (config *Config) error(entry, message string) *Error {
+	return &Error{
+		File:    config.file,
+		Entry:   entry,
+		Line:    config.lines[entry],
+		Message: message,
+	}
+}
+
+func (reg *Registry) resolveMiddleware(names []string) ([]hyper.Middleware, []string) {
+	var middleware []hyper.Middleware
+	var problems []string
+
+	for _, name := range names {
+		m, ok := reg.middleware[name]
+		if !ok {
+			problems = append(problems, unknownName("middleware", name, reg.middlewareNames()))
+			continue
+		}
+
+		middleware = append(middleware, m)
+	}
+
+	return middleware, problems
+}
+
+func (reg *Registry) handlerNames() []string {
+	names := make([]string, 0, len(reg.handlers))
+	for name := range reg.handlers {
+		names = append(names, name)
+	}
+
+	return names
+}
+
+func (reg *Registry) middlewareNames() []string {
+	names := make([]string, 0, len(reg.middleware))
+	for name := range reg.middleware {
+		names = append(names, name)
+	}
+
+	return names
+}
+
+// unknownName describes the unknown name, suggesting the closest registered one.
+func unknownName(kind, name string, known []string) string {
+	if name == "" {
+		return kind + " is required"
+	}
+
+	message := fmt.Sprintf("unknown %s '%s'", kind, name)
+
+	sort.Strings(known)
+
+	best, bestDistance := "", len(name)/2+1
+	for _, candidate := range known {
+		if distance := editDistance(strings.ToLower(name), strings.ToLower(candidate)); distance < bestDistance {
+			best, bestDistance = candidate, distance
+		}
+	}
+
+	if best != "" {
+		message += fmt.Sprintf(", did you mean '%s'?", best)
+	}
+
+	return message
+}
+
+// editDistance returns the Levenshtein distance of the two strings.
+func editDistance(a, b string) int {
+	previous := make([]int, len(b)+1)
+	current := make([]int, len(b)+1)
+
+	for j := range previous {
+		previous[j] = j
+	}
+
+	for i := 1; i <= len(a); i++ {
+		current[0] = i
+
+		for j := 1; j <= len(b); j++ {
+			cost := 1
+			if a[i-1] == b[j-1] {
+				cost = 0
+			}
+
+			current[j] = min(previous[j]+1, current[j-1]+1, previous[j-1]+cost)
+		}
+
+		previous, current = current, previous
+	}
+
+	return previous[len(b)]
+}
+
+func min(values ...int) int {
+	result := values[0]
+	for _, value := range values[1:] {
+		if value < result {
+			result = value
+		}
+	}
+
+	return result
+}
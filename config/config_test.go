@@ -0,0 +1,178 @@
+package config
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/bencicandrej/hyper-router"
+)
+
+func named(name string) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		w.Write([]byte(name))
+	})
+}
+
+func header(value string) hyper.Middleware {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+			w.Header().Add("X-Middleware", value)
+			next.ServeHTTP(w, req)
+		})
+	}
+}
+
+func registry() *Registry {
+	return NewRegistry().
+		Handler("showUser", named("show")).
+		Handler("deleteUser", named("delete")).
+		Middleware("logger", header("logger")).
+		Middleware("auth", header("auth"))
+}
+
+func writeConfig(t *testing.T, name, content string) string {
+	file := filepath.Join(t.TempDir(), name)
+	if err := os.WriteFile(file, []byte(content), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	return file
+}
+
+func TestLoad(t *testing.T) {
+	file := writeConfig(t, "routes.yaml", `
+middleware: [logger]
+routes:
+  - method: GET
+    pattern: /users/:id
+    handler: showUser
+    middleware: [auth]
+    name: users.show
+    meta:
+      owner: accounts
+  - method: DELETE
+    pattern: /users/:id
+    handler: deleteUser
+    disabled: true
+redirects:
+  - from: /people/:id
+    to: /users/:id
+    code: 301
+`)
+
+	router := hyper.NewRouter()
+	if err := Load(file, router, registry()); err != nil {
+		t.Fatalf("Load(): got error %s", err)
+	}
+
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/users/1", nil))
+
+	if w.Body.String() != "show" || strings.Join(w.Header()["X-Middleware"], ",") != "logger,auth" {
+		t.Errorf("Load(): got %q with middleware %v", w.Body, w.Header()["X-Middleware"])
+	}
+
+	match, _ := router.Lookup(http.MethodGet, "/users/1")
+	if match.Name != "users.show" || match.Route.Meta["owner"] != "accounts" {
+		t.Errorf("Load(): got route %+v", match.Route)
+	}
+
+	if _, ok := router.Lookup(http.MethodDelete, "/users/1"); ok {
+		t.Errorf("Load(): registered a disabled route")
+	}
+
+	w = httptest.NewRecorder()
+	router.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/people/1", nil))
+
+	if w.Code != http.StatusMovedPermanently || w.Header().Get("Location") != "/users/1" {
+		t.Errorf("Load(): got redirect %d to %s", w.Code, w.Header().Get("Location"))
+	}
+}
+
+func TestLoadJSON(t *testing.T) {
+	file := writeConfig(t, "routes.json", `{"routes": [{"method": "*", "pattern": "/users/:id", "handler": "showUser"}]}`)
+
+	router := hyper.NewRouter()
+	if err := Load(file, router, registry()); err != nil {
+		t.Fatalf("Load(): got error %s", err)
+	}
+
+	if _, ok := router.Lookup(http.MethodPut, "/users/1"); !ok {
+		t.Errorf("Load(): route not registered")
+	}
+}
+
+func TestLoadErrors(t *testing.T) {
+	file := writeConfig(t, "routes.yaml", `middleware: [loger]
+routes:
+  - method: GET
+    pattern: /users/:id
+    handler: showUsers
+  - method: GET
+    pattern: /about
+    handler: about
+    middleware: [auth, admin]
+  - pattern: /missing
+    handler: showUser
+`)
+
+	router := hyper.NewRouter()
+	err := Load(file, router, registry())
+
+	expected := []string{
+		file + ": middleware: unknown middleware 'loger', did you mean 'logger'?",
+		file + ": routes[0] (line 3): unknown handler 'showUsers', did you mean 'showUser'?",
+		file + ": routes[1] (line 6): unknown handler 'about'",
+		file + ": routes[1] (line 6): unknown middleware 'admin'",
+		file + ": routes[2] (line 10): method and pattern are required",
+	}
+
+	if err == nil || err.Error() != strings.Join(expected, "\n") {
+		t.Errorf("Load(): got error\n%v\nwanted\n%s", err, strings.Join(expected, "\n"))
+	}
+
+	if len(router.Routes()) != 0 {
+		t.Errorf("Load(): registered routes on error")
+	}
+}
+
+func TestLoadConflict(t *testing.T) {
+	file := writeConfig(t, "routes.yaml", `routes:
+  - {method: GET, pattern: /users, handler: showUser}
+  - {method: GET, pattern: /users/:id, handler: showUser}
+  - {method: GET, pattern: /users/:id, handler: showUser}
+  - {method: GET, pattern: /about, handler: showUser}
+redirects:
+  - {from: /people/:id, to: /users/:uid, code: 301}
+`)
+
+	router := hyper.NewRouter()
+	router.Get("/about", named("about"))
+
+	err := Load(file, router, registry())
+
+	expected := []string{
+		file + ": routes[2] (line 4): handler for route '/users/:id' already exists",
+		file + ": routes[3] (line 5): handler for route '/about' already exists",
+		file + ": redirects[0] (line 7): parameter 'uid' of '/users/:uid' is not defined in '/people/:id'",
+	}
+
+	if err == nil || err.Error() != strings.Join(expected, "\n") {
+		t.Errorf("Load(): got error\n%v\nwanted\n%s", err, strings.Join(expected, "\n"))
+	}
+
+	if routes := router.Routes(); len(routes) != 1 || routes[0].Pattern != "/about" {
+		t.Errorf("Load(): registered routes on conflict")
+	}
+}
+
+func TestParseUnknownField(t *testing.T) {
+	_, err := Parse([]byte("routes:\n  - method: GET\n    handlr: showUser\n"))
+	if err == nil || !strings.Contains(err.Error(), "line 3: field handlr not found") {
+		t.Errorf("Parse(): got error %v", err)
+	}
+}
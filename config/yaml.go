@@ -0,0 +1,651 @@
+package config
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+)
+
+// yamlNumber matches the plain scalars resolved to numbers. They are the
+// numbers of the JSON grammar, so they convert to JSON as they are.
+var yamlNumber = regexp.MustCompile(`^-?(0|[1-9][0-9]*)(\.[0-9]+)?([eE][-+]?[0-9]+)?$`)
+
+type yamlKind uint8
+
+const (
+	scalarNode yamlKind = iota
+	sequenceNode
+	mappingNode
+)
+
+// yamlNode is a node of a YAML document. The content of a sequence
+// holds its items, and the content of a mapping its keys and values,
+// alternately, so the keys keep their order and their lines.
+type yamlNode struct {
+	kind  yamlKind
+	value string
+	// quoted scalars are always strings.
+	quoted  bool
+	line    int
+	content []*yamlNode
+}
+
+// yamlError is a syntax error of the YAML document.
+type yamlError struct {
+	line    int
+	message string
+}
+
+func (err *yamlError) Error() string {
+	return fmt.Sprintf("line %d: %s", err.line, err.message)
+}
+
+// yamlParser reads the subset of YAML the configuration files are
+// written in: block and flow mappings and sequences, plain and quoted
+// scalars and comments. JSON documents are flow mappings, so they are
+// read the same. Anchors, aliases, tags, block scalars and multiple
+// documents are not supported.
+type yamlParser struct {
+	data []byte
+	pos  int
+	// line is the line of the position, and lineStart its offset.
+	line      int
+	lineStart int
+}
+
+// parseYAML parses the YAML document, or returns nil if it is empty.
+func parseYAML(data []byte) (root *yamlNode, err error) {
+	p := &yamlParser{data: data, line: 1}
+
+	// The parser panics with a *yamlError, so the syntax
+	// errors do not have to be passed through every call.
+	defer func() {
+		if recovered := recover(); recovered != nil {
+			syntaxErr, ok := recovered.(*yamlError)
+			if !ok {
+				panic(recovered)
+			}
+
+			root, err = nil, syntaxErr
+		}
+	}()
+
+	indent := p.nextLine()
+	if p.isMarker("---") {
+		p.pos += 3
+		indent = p.next()
+	}
+
+	if indent == -1 {
+		return nil, nil
+	}
+
+	root = p.parseBlock(indent)
+
+	if p.isMarker("...") {
+		p.pos += 3
+		p.next()
+	}
+
+	switch {
+	case p.isMarker("---"):
+		p.fail("multiple documents are not supported")
+	case p.pos < len(p.data):
+		p.fail("unexpected %q at the end of the document", p.token())
+	}
+
+	return root, nil
+}
+
+func (p *yamlParser) fail(format string, args ...interface{}) {
+	panic(&yamlError{p.line, fmt.Sprintf(format, args...)})
+}
+
+func (p *yamlParser) peek() byte {
+	if p.pos < len(p.data) {
+		return p.data[p.pos]
+	}
+
+	return 0
+}
+
+// peekAt returns the byte at the offset from the position,
+// or a newline past the end, which ends all the tokens.
+func (p *yamlParser) peekAt(offset int) byte {
+	if p.pos+offset < len(p.data) {
+		return p.data[p.pos+offset]
+	}
+
+	return '\n'
+}
+
+func (p *yamlParser) column() int {
+	return p.pos - p.lineStart
+}
+
+// token returns the text from the position to the next white space.
+func (p *yamlParser) token() string {
+	end := p.pos
+	for end < len(p.data) && !isYAMLSpace(p.data[end]) {
+		end++
+	}
+
+	return string(p.data[p.pos:end])
+}
+
+// isMarker checks if the line starts with the document marker.
+func (p *yamlParser) isMarker(marker string) bool {
+	return p.column() == 0 && bytes.HasPrefix(p.data[p.pos:], []byte(marker)) && isYAMLSpace(p.peekAt(len(marker)))
+}
+
+// isDocumentEnd checks if the line is a marker ending the document.
+func (p *yamlParser) isDocumentEnd() bool {
+	return p.isMarker("---") || p.isMarker("...")
+}
+
+func (p *yamlParser) skipSpaces() {
+	for p.peek() == ' ' || p.peek() == '\t' {
+		p.pos++
+	}
+}
+
+// atLineEnd checks if only the spaces and a comment are left on the line.
+func (p *yamlParser) atLineEnd() bool {
+	p.skipSpaces()
+
+	return p.pos == len(p.data) || strings.IndexByte("\n\r#", p.data[p.pos]) != -1
+}
+
+// endLine moves to the start of the next line, failing
+// if anything but a comment is left on the current one.
+func (p *yamlParser) endLine() {
+	if !p.atLineEnd() {
+		p.fail("unexpected %q", p.token())
+	}
+
+	for p.pos < len(p.data) && p.data[p.pos] != '\n' {
+		p.pos++
+	}
+
+	if p.pos < len(p.data) {
+		p.pos++
+		p.line++
+		p.lineStart = p.pos
+	}
+}
+
+// nextLine moves from the start of a line to the content of the next
+// line that is not blank or a comment, and returns its indentation,
+// or -1 at the end of the document.
+func (p *yamlParser) nextLine() int {
+	for {
+		for p.peek() == ' ' {
+			p.pos++
+		}
+
+		if p.pos == len(p.data) {
+			return -1
+		}
+
+		if !p.atLineEnd() {
+			if bytes.IndexByte(p.data[p.lineStart:p.pos], '\t') != -1 {
+				p.fail("tabs are not allowed in the indentation")
+			}
+
+			return p.column()
+		}
+
+		p.endLine()
+	}
+}
+
+// next ends the current line and moves to the content of the next one.
+func (p *yamlParser) next() int {
+	p.endLine()
+
+	return p.nextLine()
+}
+
+// indent returns the indentation of the content at the
+// position, or -1 at the end of the document.
+func (p *yamlParser) indent() int {
+	if p.pos == len(p.data) {
+		return -1
+	}
+
+	return p.column()
+}
+
+func (p *yamlParser) isSequenceItem() bool {
+	return p.peek() == '-' && isYAMLSpace(p.peekAt(1))
+}
+
+// isMappingKey checks if the line continues with a key and a ':'.
+func (p *yamlParser) isMappingKey() bool {
+	i := p.pos
+
+	switch p.peek() {
+	case '"', '\'':
+		quote := p.peek()
+		for i++; i < len(p.data) && p.data[i] != quote && p.data[i] != '\n'; i++ {
+			if quote == '"' && p.data[i] == '\\' {
+				i++
+			}
+		}
+
+		for i++; i < len(p.data) && p.data[i] == ' '; i++ {
+		}
+
+		return i < len(p.data) && p.data[i] == ':' && (i+1 == len(p.data) || isYAMLSpace(p.data[i+1]))
+	case '[', '{', '#', '-', '?':
+		if p.peek() != '-' || p.isSequenceItem() {
+			return false
+		}
+	}
+
+	for ; i < len(p.data) && p.data[i] != '\n'; i++ {
+		switch {
+		case p.data[i] == ':' && (i+1 == len(p.data) || isYAMLSpace(p.data[i+1])):
+			return true
+		case p.data[i] == '#' && i > p.pos && isYAMLSpace(p.data[i-1]):
+			return false
+		}
+	}
+
+	return false
+}
+
+// parseBlock parses the block node at the position, which
+// ends at the first line indented less than the indentation.
+func (p *yamlParser) parseBlock(indent int) *yamlNode {
+	switch {
+	case p.isSequenceItem():
+		return p.parseSequence(indent)
+	case p.isMappingKey():
+		return p.parseMapping(indent)
+	}
+
+	node := p.parseValue()
+	p.next()
+
+	return node
+}
+
+func (p *yamlParser) parseSequence(indent int) *yamlNode {
+	node := &yamlNode{kind: sequenceNode, line: p.line}
+
+	for p.indent() == indent && p.isSequenceItem() {
+		line := p.line
+
+		p.pos++
+		if !p.atLineEnd() {
+			// A compact item, e.g. "- key: value", continues
+			// at the indentation of its first character.
+			node.content = append(node.content, p.parseBlock(p.column()))
+			continue
+		}
+
+		if next := p.next(); next > indent {
+			node.content = append(node.content, p.parseBlock(next))
+		} else {
+			node.content = append(node.content, &yamlNode{kind: scalarNode, line: line})
+		}
+	}
+
+	if p.indent() > indent {
+		p.fail("bad indentation of a sequence item")
+	}
+
+	return node
+}
+
+func (p *yamlParser) parseMapping(indent int) *yamlNode {
+	node := &yamlNode{kind: mappingNode, line: p.line}
+
+	for p.indent() == indent && !p.isDocumentEnd() {
+		if !p.isMappingKey() {
+			p.fail("expected a mapping key, got %q", p.token())
+		}
+
+		key := p.parseKey()
+
+		var value *yamlNode
+		if line := p.line; p.atLineEnd() {
+			// The value is on the next lines. A sequence may
+			// have the same indentation as its key.
+			switch next := p.next(); {
+			case next > indent:
+				value = p.parseBlock(next)
+			case next == indent && p.isSequenceItem():
+				value = p.parseSequence(indent)
+			default:
+				value = &yamlNode{kind: scalarNode, line: line}
+			}
+		} else {
+			value = p.parseValue()
+			p.next()
+		}
+
+		node.add(key, value)
+	}
+
+	if p.indent() > indent {
+		p.fail("bad indentation of a mapping entry")
+	}
+
+	return node
+}
+
+// parseKey parses the key of a block mapping and the ':' after it.
+func (p *yamlParser) parseKey() *yamlNode {
+	var key *yamlNode
+	if p.peek() == '"' || p.peek() == '\'' {
+		key = p.parseQuoted()
+	} else {
+		start := p.pos
+		for p.peek() != ':' || !isYAMLSpace(p.peekAt(1)) {
+			p.pos++
+		}
+
+		key = &yamlNode{kind: scalarNode, value: strings.TrimRight(string(p.data[start:p.pos]), " \t"), line: p.line}
+	}
+
+	p.skipSpaces()
+	p.pos++
+
+	return key
+}
+
+// parseValue parses the scalar or the flow collection
+// in a block, which ends with the line, or a comment.
+func (p *yamlParser) parseValue() *yamlNode {
+	switch p.peek() {
+	case '[', '{':
+		return p.parseFlow()
+	case '"', '\'':
+		return p.parseQuoted()
+	}
+
+	p.checkIndicator()
+
+	start := p.pos
+	for p.pos < len(p.data) && p.data[p.pos] != '\n' && !(p.data[p.pos] == '#' && isYAMLSpace(p.data[p.pos-1])) {
+		p.pos++
+	}
+
+	return &yamlNode{kind: scalarNode, value: strings.TrimRight(string(p.data[start:p.pos]), " \t\r"), line: p.line}
+}
+
+// checkIndicator fails on the indicators of the unsupported features.
+func (p *yamlParser) checkIndicator() {
+	switch c := p.peek(); c {
+	case '&', '*', '!', '|', '>', '%', '@', '`':
+		p.fail("%q is not supported, quote the value %q", c, p.token())
+	}
+}
+
+// skipFlowSpaces skips the white space, the line
+// breaks and the comments in a flow collection.
+func (p *yamlParser) skipFlowSpaces() {
+	for p.pos < len(p.data) {
+		switch p.data[p.pos] {
+		case ' ', '\t', '\r':
+			p.pos++
+		case '\n':
+			p.pos++
+			p.line++
+			p.lineStart = p.pos
+		case '#':
+			for p.pos < len(p.data) && p.data[p.pos] != '\n' {
+				p.pos++
+			}
+		default:
+			return
+		}
+	}
+}
+
+// parseFlow parses the flow collection, which may span several lines.
+func (p *yamlParser) parseFlow() *yamlNode {
+	node := &yamlNode{kind: sequenceNode, line: p.line}
+	end := byte(']')
+	if p.peek() == '{' {
+		node.kind, end = mappingNode, '}'
+	}
+
+	for p.pos++; ; {
+		p.skipFlowSpaces()
+		if p.peek() == end {
+			p.pos++
+			return node
+		}
+
+		item := p.parseFlowItem()
+
+		if node.kind == mappingNode {
+			if item.kind != scalarNode {
+				p.fail("mapping keys must be scalars")
+			}
+
+			p.skipFlowSpaces()
+			if p.peek() != ':' {
+				p.fail("expected ':' after the key %q", item.value)
+			}
+
+			p.pos++
+			p.skipFlowSpaces()
+
+			if c := p.peek(); c == ',' || c == end {
+				node.add(item, &yamlNode{kind: scalarNode, line: p.line})
+			} else {
+				node.add(item, p.parseFlowItem())
+			}
+		} else {
+			node.content = append(node.content, item)
+		}
+
+		p.skipFlowSpaces()
+		switch p.peek() {
+		case ',':
+			p.pos++
+		case end:
+		case 0:
+			p.fail("unterminated flow collection, missing '%c'", end)
+		default:
+			p.fail("expected ',' or '%c', got %q", end, p.token())
+		}
+	}
+}
+
+func (p *yamlParser) parseFlowItem() *yamlNode {
+	switch p.peek() {
+	case '[', '{':
+		return p.parseFlow()
+	case '"', '\'':
+		return p.parseQuoted()
+	case 0:
+		p.fail("unterminated flow collection")
+	}
+
+	p.checkIndicator()
+
+	start := p.pos
+	for p.pos < len(p.data) {
+		c := p.data[p.pos]
+		if strings.IndexByte(",[]{}\r\n", c) != -1 || c == ':' && strings.IndexByte(",[]{} \t\r\n", p.peekAt(1)) != -1 || c == '#' && isYAMLSpace(p.data[p.pos-1]) {
+			break
+		}
+
+		p.pos++
+	}
+
+	value := strings.TrimRight(string(p.data[start:p.pos]), " \t")
+	if value == "" {
+		p.fail("unexpected %q", p.peek())
+	}
+
+	return &yamlNode{kind: scalarNode, value: value, line: p.line}
+}
+
+// parseQuoted parses the single or double-quoted scalar, which
+// must end on the same line. The double-quoted scalars support
+// the escape sequences of JSON and YAML.
+func (p *yamlParser) parseQuoted() *yamlNode {
+	quote := p.peek()
+	node := &yamlNode{kind: scalarNode, quoted: true, line: p.line}
+
+	var value strings.Builder
+	for p.pos++; ; p.pos++ {
+		if p.pos == len(p.data) || p.data[p.pos] == '\n' {
+			p.fail("unterminated quoted scalar")
+		}
+
+		c := p.data[p.pos]
+		switch {
+		case c == quote && quote == '\'' && p.peekAt(1) == '\'':
+			value.WriteByte('\'')
+			p.pos++
+		case c == quote:
+			p.pos++
+			node.value = value.String()
+			return node
+		case c == '\\' && quote == '"':
+			p.pos++
+			p.parseEscape(&value)
+		default:
+			value.WriteByte(c)
+		}
+	}
+}
+
+// yamlEscapes are the single character escape sequences.
+var yamlEscapes = map[byte]string{
+	'0': "\x00", 'a': "\a", 'b': "\b", 't': "\t", '\t': "\t", 'n': "\n", 'v': "\v", 'f': "\f",
+	'r': "\r", 'e': "\x1b", ' ': " ", '"': "\"", '/': "/", '\\': "\\", 'N': "\u0085", '_': " ",
+}
+
+// parseEscape writes the character of the escape sequence at the position.
+func (p *yamlParser) parseEscape(value *strings.Builder) {
+	c := p.peek()
+	if escaped, ok := yamlEscapes[c]; ok {
+		value.WriteString(escaped)
+		return
+	}
+
+	size := map[byte]int{'x': 2, 'u': 4, 'U': 8}[c]
+	if size == 0 || p.pos+size >= len(p.data) {
+		p.fail("invalid escape sequence '\\%c'", c)
+	}
+
+	code, err := strconv.ParseUint(string(p.data[p.pos+1:p.pos+1+size]), 16, 32)
+	if err != nil || !utf8.ValidRune(rune(code)) {
+		p.fail("invalid escape sequence '\\%s'", p.data[p.pos:p.pos+1+size])
+	}
+
+	value.WriteRune(rune(code))
+	p.pos += size
+}
+
+// add adds the key and the value to the mapping, failing on duplicate keys.
+func (n *yamlNode) add(key, value *yamlNode) {
+	for i := 0; i < len(n.content); i += 2 {
+		if n.content[i].value == key.value {
+			panic(&yamlError{key.line, fmt.Sprintf("mapping key %q already defined at line %d", key.value, n.content[i].line)})
+		}
+	}
+
+	n.content = append(n.content, key, value)
+}
+
+// keyLine returns the line of the key of the mapping,
+// or the line of the node if it has no such key.
+func (n *yamlNode) keyLine(key string) int {
+	for i := 0; n.kind == mappingNode && i < len(n.content); i += 2 {
+		if n.content[i].value == key {
+			return n.content[i].line
+		}
+	}
+
+	return n.line
+}
+
+// decoded returns the value of the node, as json.Unmarshal would decode
+// it into an interface{}. Plain scalars are resolved to null, booleans
+// and numbers by the YAML core schema, and to strings otherwise.
+func (n *yamlNode) decoded() interface{} {
+	switch n.kind {
+	case sequenceNode:
+		items := make([]interface{}, len(n.content))
+		for i, item := range n.content {
+			items[i] = item.decoded()
+		}
+
+		return items
+	case mappingNode:
+		values := make(map[string]interface{}, len(n.content)/2)
+		for i := 0; i < len(n.content); i += 2 {
+			values[n.content[i].value] = n.content[i+1].decoded()
+		}
+
+		return values
+	}
+
+	if n.quoted {
+		return n.value
+	}
+
+	switch n.value {
+	case "", "~", "null", "Null", "NULL":
+		return nil
+	case "true", "True", "TRUE":
+		return true
+	case "false", "False", "FALSE":
+		return false
+	}
+
+	if yamlNumber.MatchString(n.value) {
+		return json.Number(n.value)
+	}
+
+	return n.value
+}
+
+// decode stores the node in the value the same as json.Unmarshal
+// would store the JSON of the node, and reports the unknown fields
+// and the mismatched types with the line of their keys.
+func (n *yamlNode) decode(value interface{}) error {
+	data, err := json.Marshal(n.decoded())
+	if err != nil {
+		return &yamlError{n.line, err.Error()}
+	}
+
+	decoder := json.NewDecoder(bytes.NewReader(data))
+	decoder.DisallowUnknownFields()
+
+	err = decoder.Decode(value)
+	if err == nil {
+		return nil
+	}
+
+	if field := strings.TrimPrefix(err.Error(), "json: unknown field "); field != err.Error() {
+		name, _ := strconv.Unquote(field)
+
+		return &yamlError{n.keyLine(name), fmt.Sprintf("field %s not found in type %s", name, strings.TrimPrefix(fmt.Sprintf("%T", value), "*"))}
+	}
+
+	var typeErr *json.UnmarshalTypeError
+	if errors.As(err, &typeErr) {
+		return &yamlError{n.keyLine(typeErr.Field[strings.LastIndexByte(typeErr.Field, '.')+1:]), strings.TrimPrefix(err.Error(), "json: ")}
+	}
+
+	return &yamlError{n.line, strings.TrimPrefix(err.Error(), "json: ")}
+}
+
+func isYAMLSpace(c byte) bool {
+	return c == ' ' || c == '\t' || c == '\r' || c == '\n'
+}
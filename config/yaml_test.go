@@ -0,0 +1,101 @@
+package config
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+)
+
+func TestParseYAML(t *testing.T) {
+	tests := []struct {
+		document string
+		want     string
+	}{
+		{"", `null`},
+		{"# only a comment\n", `null`},
+		{"a: 1\nb: text # comment\nc:\n", `{"a":1,"b":"text","c":null}`},
+		{"---\na: [1, two, \"three\"]\n...\n", `{"a":[1,"two","three"]}`},
+		{"list:\n- a\n- b\nnext: true\n", `{"list":["a","b"],"next":true}`},
+		{"list:\n  - a: 1\n    b: 2\n  -\n    c: 3\n  - - x\n    - y\n", `{"list":[{"a":1,"b":2},{"c":3},["x","y"]]}`},
+		{"outer:\n  inner:\n    deep: x\n  other: y\n", `{"outer":{"inner":{"deep":"x"},"other":"y"}}`},
+		{"- {method: GET, pattern: /users/:id, to: 'http://x/#y'}\n", `[{"method":"GET","pattern":"/users/:id","to":"http://x/#y"}]`},
+		{"a: {b: [1, {c: d}], e: }\n", `{"a":{"b":[1,{"c":"d"}],"e":null}}`},
+		{"a: [\n  1, # one\n  2\n]\nb: c\n", `{"a":[1,2],"b":"c"}`},
+		{"a: 'it''s'\nb: \"tab\\tnew\\nline \\u00e9\\x41\"\n", `{"a":"it's","b":"tab\tnew\nline éA"}`},
+		{"a: \"1\"\nb: 1.5e3\nc: 0x10\nd: 01\ne: -0\nf: ~\ng: Null\nh: TRUE\ni: yes\n", `{"a":"1","b":1.5e3,"c":"0x10","d":"01","e":-0,"f":null,"g":null,"h":true,"i":"yes"}`},
+		{"\"quoted key\": 1\n'single': 2\n", `{"quoted key":1,"single":2}`},
+		{"a: b: c\n", `{"a":"b: c"}`},
+		{"just a scalar\n", `"just a scalar"`},
+		{"{\n  \"routes\": [\n    {\"method\": \"*\", \"code\": 301}\n  ]\n}\n", `{"routes":[{"code":301,"method":"*"}]}`},
+		{"a: 1\r\nb:\r\n  - x\r\n", `{"a":1,"b":["x"]}`},
+	}
+
+	for _, test := range tests {
+		root, err := parseYAML([]byte(test.document))
+		if err != nil {
+			t.Errorf("parseYAML(%q): got error %s", test.document, err)
+			continue
+		}
+
+		var value interface{}
+		if root != nil {
+			value = root.decoded()
+		}
+
+		got, _ := json.Marshal(value)
+		if string(got) != test.want {
+			t.Errorf("parseYAML(%q): got %s, wanted %s", test.document, got, test.want)
+		}
+	}
+}
+
+func TestParseYAMLErrors(t *testing.T) {
+	tests := []struct {
+		document string
+		err      string
+	}{
+		{"a: 1\n\tb: 2\n", "line 2: tabs are not allowed in the indentation"},
+		{"a: 1\nb: 2\na: 3\n", `line 3: mapping key "a" already defined at line 1`},
+		{"a: [1, 2\nb: 3\n", "line 2: expected ',' or ']'"},
+		{"a: [1, 2", "line 1: unterminated flow collection"},
+		{"a: {b c}\n", "line 1: expected ':' after the key \"b c\""},
+		{"method: *\n", "line 1: '*' is not supported, quote the value \"*\""},
+		{"a: &anchor 1\n", "line 1: '&' is not supported"},
+		{"a: |\n  text\n", "line 1: '|' is not supported"},
+		{"a: 1\n---\nb: 2\n", "line 2: multiple documents are not supported"},
+		{"a:\n    b: 1\n  c: 2\n", "line 3: bad indentation of a mapping entry"},
+		{"- a\n  - b\n", "line 2: bad indentation of a sequence item"},
+		{"a: 1\n- b\n", "line 2: expected a mapping key, got \"-\""},
+		{"a: \"open\n", "line 1: unterminated quoted scalar"},
+		{"a: \"\\q\"\n", "line 1: invalid escape sequence '\\q'"},
+		{"a: [1] b\n", "line 1: unexpected \"b\""},
+	}
+
+	for _, test := range tests {
+		_, err := parseYAML([]byte(test.document))
+		if err == nil || !strings.HasPrefix(err.Error(), test.err) {
+			t.Errorf("parseYAML(%q): got error %v, wanted %q", test.document, err, test.err)
+		}
+	}
+}
+
+func TestParseFieldErrors(t *testing.T) {
+	tests := []struct {
+		document string
+		err      string
+	}{
+		{"routes:\n  - method: GET\n    handler: [a]\n", "line 3: cannot unmarshal array into Go struct field Route.handler of type string"},
+		{"{\"routes\": [\n  {\"method\": \"GET\",\n   \"handlr\": \"showUser\"}\n]}\n", "line 3: field handlr not found in type config.Route"},
+		{"redirects:\n  - from: /a\n    target: /b\n", "line 3: field target not found in type hyper.Rule"},
+		{"route:\n  - method: GET\n", "line 1: field route not found in type config.Config"},
+		{"routes: {method: GET}\n", "line 1: routes must be a list"},
+		{"- a\n", "line 1: configuration must be a mapping"},
+	}
+
+	for _, test := range tests {
+		_, err := Parse([]byte(test.document))
+		if err == nil || err.Error() != test.err {
+			t.Errorf("Parse(%q): got error %v, wanted %q", test.document, err, test.err)
+		}
+	}
+}
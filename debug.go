@@ -0,0 +1,232 @@
+package hyper
+
+import (
+	"encoding/json"
+	"html/template"
+	"net/http"
+	"reflect"
+	"runtime"
+	"sort"
+	"strings"
+
+	"github.com/bencicandrej/hyper-router/params"
+)
+
+// Routes returns all routes registered on the router, sorted
+// by their pattern and method. Routes of the API versions
+// are not included, see router.Version.
+func (r *Router) Routes() []*Route {
+	var routes []*Route
+	if r.tree != nil {
+		routes = collectRoutes(r.tree, routes)
+	}
+
+	sort.SliceStable(routes, func(i, j int) bool {
+		if routes[i].Pattern != routes[j].Pattern {
+			return routes[i].Pattern < routes[j].Pattern
+		}
+
+		return routes[i].Method < routes[j].Method
+	})
+
+	return routes
+}
+
+func collectRoutes(n *node, routes []*Route) []*Route {
+	for _, handler := range n.handlers {
+		if handler != nil {
+			routes = append(routes, handler.(*Route))
+		}
+	}
+
+	for _, child := range n.children {
+		routes = collectRoutes(child, routes)
+	}
+
+	return routes
+}
+
+// MiddlewareNames returns the function names of the route middleware,
+// in the order of the request flow.
+func (route *Route) MiddlewareNames() []string {
+	names := make([]string, len(route.middleware))
+	for i, middleware := range route.middleware {
+		names[i] = funcName(middleware)
+	}
+
+	return names
+}
+
+// funcName returns the name of the function, e.g. main.Auth.func1.
+func funcName(f interface{}) string {
+	if fn := runtime.FuncForPC(reflect.ValueOf(f).Pointer()); fn != nil {
+		return fn.Name()
+	}
+
+	return "unknown"
+}
+
+type debugRoute struct {
+	Version    int                    `json:"version,omitempty"`
+	Method     string                 `json:"method"`
+	Pattern    string                 `json:"pattern"`
+	Name       string                 `json:"name,omitempty"`
+	Middleware []string               `json:"middleware,omitempty"`
+	Meta       map[string]interface{} `json:"meta,omitempty"`
+	Hits       uint64                 `json:"hits"`
+}
+
+type debugMatch struct {
+	Method   string        `json:"method"`
+	Path     string        `json:"path"`
+	Route    *debugRoute   `json:"route,omitempty"`
+	Params   params.Params `json:"params,omitempty"`
+	Allowed  []string      `json:"allowed,omitempty"`
+	Redirect string        `json:"redirect,omitempty"`
+}
+
+type debugPage struct {
+	Routes []debugRoute `json:"routes"`
+	Match  *debugMatch  `json:"match,omitempty"`
+}
+
+// DebugHandler returns a http.Handler that shows all routes of the
+// router, with their middleware, metadata and the number of requests
+// they served, if router.CountHits is set. It also tests which route would serve the method and
+// path given in the query, e.g. ?method=GET&path=/users/42.
+//
+// The routes are rendered as an HTML page, or as JSON if the request
+// accepts application/json or the format=json query is set. The handler
+// exposes the internals of the application, so it should only be
+// mounted on an internal port.
+func DebugHandler(r *Router) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		page := debugPage{
+			Routes: debugRoutes(r),
+		}
+
+		query := req.URL.Query()
+		if path := query.Get("path"); path != "" {
+			method := query.Get("method")
+			if method == "" {
+				method = http.MethodGet
+			}
+
+			page.Match = debugLookup(r, method, path)
+		}
+
+		if query.Get("format") == "json" || strings.Contains(req.Header.Get("Accept"), "application/json") {
+			w.Header().Set("Content-Type", "application/json")
+
+			encoder := json.NewEncoder(w)
+			encoder.SetIndent("", "  ")
+			encoder.Encode(page)
+			return
+		}
+
+		w.Header().Set("Content-Type", "text/html; charset=utf-8")
+		debugTemplate.Execute(w, page)
+	})
+}
+
+func debugRoutes(r *Router) []debugRoute {
+	var routes []debugRoute
+	for _, route := range r.Routes() {
+		routes = append(routes, describeRoute(route, 0))
+	}
+
+	for i := len(r.versionOrder) - 1; i >= 0; i-- {
+		version := r.versionOrder[i]
+		for _, route := range r.versions[version].Routes() {
+			routes = append(routes, describeRoute(route, version))
+		}
+	}
+
+	return routes
+}
+
+func describeRoute(route *Route, version int) debugRoute {
+	return debugRoute{
+		Version:    version,
+		Method:     route.Method,
+		Pattern:    route.Pattern,
+		Name:       route.Name,
+		Middleware: route.MiddlewareNames(),
+		Meta:       route.Meta,
+		Hits:       route.Hits(),
+	}
+}
+
+func debugLookup(r *Router, method, path string) *debugMatch {
+	match, ok := r.Lookup(method, path)
+
+	result := &debugMatch{
+		Method:   method,
+		Path:     path,
+		Params:   match.Params,
+		Allowed:  match.Allowed,
+		Redirect: match.Redirect,
+	}
+
+	if ok {
+		described := describeRoute(match.Route, 0)
+		result.Route = &described
+	}
+
+	return result
+}
+
+var debugTemplate = template.Must(template.New("debug").Parse(`<!DOCTYPE html>
+<html>
+<head>
+<meta charset="utf-8">
+<title>Routes</title>
+<style>
+body { font-family: sans-serif; margin: 2em; }
+table { border-collapse: collapse; }
+th, td { border: 1px solid #ccc; padding: 0.3em 0.6em; text-align: left; vertical-align: top; }
+code { font-size: 0.9em; }
+</style>
+</head>
+<body>
+<h1>Routes</h1>
+
+<form method="get">
+<input name="method" value="{{with .Match}}{{.Method}}{{else}}GET{{end}}" size="8">
+<input name="path" value="{{with .Match}}{{.Path}}{{end}}" placeholder="/users/42" size="60">
+<button type="submit">Test URL</button>
+</form>
+
+{{with .Match}}
+<h2>{{.Method}} {{.Path}}</h2>
+{{if .Route}}
+<p>Matched <code>{{.Route.Method}} {{.Route.Pattern}}</code>{{with .Route.Name}} ({{.}}){{end}}</p>
+{{if .Params}}<table>
+<tr><th>Param</th><th>Value</th></tr>
+{{range .Params}}<tr><td>{{.Key}}</td><td><code>{{.Value}}</code></td></tr>
+{{end}}</table>{{end}}
+{{else if .Allowed}}
+<p>Method not allowed, allowed methods: {{range $i, $m := .Allowed}}{{if $i}}, {{end}}{{$m}}{{end}}</p>
+{{else if .Redirect}}
+<p>No route matched, redirect to <code>{{.Redirect}}</code> would match.</p>
+{{else}}
+<p>No route matched.</p>
+{{end}}
+{{end}}
+
+<h2>All routes</h2>
+<table>
+<tr><th>Version</th><th>Method</th><th>Pattern</th><th>Name</th><th>Middleware</th><th>Meta</th><th>Hits</th></tr>
+{{range .Routes}}<tr>
+<td>{{if .Version}}v{{.Version}}{{end}}</td>
+<td>{{.Method}}</td>
+<td><code>{{.Pattern}}</code></td>
+<td>{{.Name}}</td>
+<td>{{range .Middleware}}<code>{{.}}</code><br>{{end}}</td>
+<td>{{range $key, $value := .Meta}}{{$key}}: {{$value}}<br>{{end}}</td>
+<td>{{.Hits}}</td>
+</tr>
+{{end}}</table>
+</body>
+</html>
+`))
@@ -0,0 +1,58 @@
+package hyper
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func passthrough(next http.Handler) http.Handler {
+	return next
+}
+
+func TestDebugHandler(t *testing.T) {
+	router := NewRouter()
+	router.CountHits = true
+	router.Get("/users/:id", emptyHandler, passthrough).Named("user").WithMeta("owner", "accounts")
+	router.Delete("/users/:id", emptyHandler)
+	router.Version(2).Get("/sites", emptyHandler)
+
+	router.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/users/1", nil))
+
+	debug := DebugHandler(router)
+
+	w := httptest.NewRecorder()
+	debug.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/?format=json&method=GET&path=/users/42", nil))
+
+	var page debugPage
+	if err := json.Unmarshal(w.Body.Bytes(), &page); err != nil {
+		t.Fatalf("DebugHandler(): got invalid JSON %s", err)
+	}
+
+	if len(page.Routes) != 3 {
+		t.Fatalf("DebugHandler(): got %d routes, wanted 3", len(page.Routes))
+	}
+
+	user := page.Routes[1]
+	if user.Name != "user" || user.Hits != 1 || user.Meta["owner"] != "accounts" ||
+		len(user.Middleware) != 1 || !strings.HasSuffix(user.Middleware[0], ".passthrough") {
+		t.Errorf("DebugHandler(): got route %+v", user)
+	}
+
+	if page.Routes[2].Version != 2 || page.Routes[2].Pattern != "/sites" {
+		t.Errorf("DebugHandler(): got version route %+v", page.Routes[2])
+	}
+
+	if page.Match == nil || page.Match.Route == nil || page.Match.Route.Name != "user" || page.Match.Params[0].Value != "42" {
+		t.Errorf("DebugHandler(): got match %+v", page.Match)
+	}
+
+	w = httptest.NewRecorder()
+	debug.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/?method=POST&path=/users/42", nil))
+
+	if body := w.Body.String(); !strings.Contains(body, "/users/:id") || !strings.Contains(body, "allowed methods: DELETE, GET") {
+		t.Errorf("DebugHandler(): got HTML\n%s", body)
+	}
+}
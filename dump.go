@@ -0,0 +1,195 @@
+package hyper
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"strings"
+)
+
+// TreeFormat is the output format of router.DumpTree.
+type TreeFormat int
+
+// Formats supported by router.DumpTree.
+const (
+	// TreeText is an indented, human readable tree.
+	TreeText TreeFormat = iota
+	// TreeDOT is a Graphviz digraph, e.g. for `dot -Tsvg`.
+	TreeDOT
+	// TreeJSON is a nested JSON object of the tree nodes.
+	TreeJSON
+)
+
+// TreeNode describes a single node of the route tree.
+type TreeNode struct {
+	Label string `json:"label"`
+	// Kind is one of "static", "param" or "wildcard".
+	Kind string `json:"kind"`
+	// Path is the full path of the node, from the root.
+	Path     string      `json:"path"`
+	Routes   []TreeRoute `json:"routes,omitempty"`
+	Children []*TreeNode `json:"children,omitempty"`
+}
+
+// TreeRoute describes a route registered on a tree node.
+type TreeRoute struct {
+	Method string `json:"method"`
+	Name   string `json:"name,omitempty"`
+	Hits   uint64 `json:"hits"`
+}
+
+// Tree returns the description of the route tree, or nil
+// if no routes are registered.
+func (r *Router) Tree() *TreeNode {
+	if r.tree == nil || r.tree.isEmpty() {
+		return nil
+	}
+
+	return r.describeNode(r.tree, "")
+}
+
+func (r *Router) describeNode(n *node, prefix string) *TreeNode {
+	described := &TreeNode{
+		Label: n.label.String(),
+		Kind:  "static",
+		Path:  prefix + n.label.String(),
+	}
+
+	switch {
+	case n.isWildcard():
+		described.Kind = "wildcard"
+	case n.isParameter():
+		described.Kind = "param"
+	}
+
+	for index, handler := range n.handlers {
+		if handler == nil {
+			continue
+		}
+
+		route := handler.(*Route)
+		described.Routes = append(described.Routes, TreeRoute{
+			Method: r.methodName(index),
+			Name:   route.Name,
+			Hits:   route.Hits(),
+		})
+	}
+
+	for _, child := range n.children {
+		described.Children = append(described.Children, r.describeNode(child, described.Path))
+	}
+
+	return described
+}
+
+// DumpTree writes the route tree in the format provided. Every node
+// shows its label, kind and the methods of its routes, along with
+// the number of requests the routes have served, if router.CountHits is set.
+func (r *Router) DumpTree(w io.Writer, format TreeFormat) error {
+	tree := r.Tree()
+
+	switch format {
+	case TreeText:
+		if tree == nil {
+			_, err := fmt.Fprintln(w, "NIL TREE")
+			return err
+		}
+
+		return dumpText(w, tree, 0)
+
+	case TreeDOT:
+		return dumpDOT(w, tree)
+
+	case TreeJSON:
+		encoder := json.NewEncoder(w)
+		encoder.SetIndent("", "  ")
+
+		return encoder.Encode(tree)
+	}
+
+	return fmt.Errorf("unknown tree format %d", format)
+}
+
+func dumpText(w io.Writer, tree *TreeNode, offset int) error {
+	indent := ""
+	if offset > 0 {
+		indent = strings.Repeat("   ", offset-1) + "└── "
+	}
+
+	if _, err := fmt.Fprintf(w, "%s%s (%s)%s\n", indent, tree.Label, tree.Kind, describeRoutes(tree.Routes)); err != nil {
+		return err
+	}
+
+	for _, child := range tree.Children {
+		if err := dumpText(w, child, offset+1); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// describeRoutes formats the routes as " [GET 12, PUT]",
+// omitting the hits of the routes that were not requested.
+func describeRoutes(routes []TreeRoute) string {
+	if len(routes) == 0 {
+		return ""
+	}
+
+	described := make([]string, len(routes))
+	for i, route := range routes {
+		described[i] = route.Method
+		if route.Name != "" {
+			described[i] += " " + route.Name
+		}
+
+		if route.Hits > 0 {
+			described[i] += fmt.Sprintf(" (%d hits)", route.Hits)
+		}
+	}
+
+	return " [" + strings.Join(described, ", ") + "]"
+}
+
+func dumpDOT(w io.Writer, tree *TreeNode) error {
+	var b strings.Builder
+
+	b.WriteString("digraph routes {\n")
+	b.WriteString("\tnode [shape=box, fontname=monospace];\n")
+
+	if tree != nil {
+		id := 0
+		dumpDOTNode(&b, tree, &id)
+	}
+
+	b.WriteString("}\n")
+
+	_, err := io.WriteString(w, b.String())
+
+	return err
+}
+
+// dumpDOTNode writes the node and the edges to its children,
+// and returns the identifier of the node.
+func dumpDOTNode(b *strings.Builder, tree *TreeNode, id *int) int {
+	nodeID := *id
+	*id++
+
+	style := ""
+	switch {
+	case len(tree.Routes) > 0:
+		style = ", style=bold"
+	case tree.Kind != "static":
+		style = ", style=dashed"
+	}
+
+	label := fmt.Sprintf("%s\\n%s%s", tree.Label, tree.Kind, describeRoutes(tree.Routes))
+	fmt.Fprintf(b, "\tn%d [label=%q%s];\n", nodeID, label, style)
+
+	for _, child := range tree.Children {
+		childID := dumpDOTNode(b, child, id)
+		fmt.Fprintf(b, "\tn%d -> n%d;\n", nodeID, childID)
+	}
+
+	return nodeID
+}
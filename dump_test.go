@@ -0,0 +1,82 @@
+package hyper
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestRouterDumpTree(t *testing.T) {
+	router := NewRouter()
+	router.CountHits = true
+	router.Get("/users", emptyHandler)
+	router.Get("/users/:id", emptyHandler).Named("user")
+	router.Put("/users/:id", emptyHandler)
+	router.Get("/static/*filepath", emptyHandler)
+
+	router.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/users/1", nil))
+
+	text := &bytes.Buffer{}
+	if err := router.DumpTree(text, TreeText); err != nil {
+		t.Fatalf("router.DumpTree(TreeText): got error %s", err)
+	}
+
+	want := strings.Join([]string{
+		"/ (static)",
+		"└── users (static) [GET]",
+		"   └── / (static)",
+		"      └── :id (param) [GET user (1 hits), PUT]",
+		"└── static/ (static)",
+		"   └── *filepath (wildcard) [GET]",
+		"",
+	}, "\n")
+
+	if text.String() != want {
+		t.Errorf("router.DumpTree(TreeText): got\n%s\nwanted\n%s", text, want)
+	}
+
+	dot := &bytes.Buffer{}
+	if err := router.DumpTree(dot, TreeDOT); err != nil {
+		t.Fatalf("router.DumpTree(TreeDOT): got error %s", err)
+	}
+
+	if !strings.HasPrefix(dot.String(), "digraph routes {") || strings.Count(dot.String(), " -> ") != 5 {
+		t.Errorf("router.DumpTree(TreeDOT): got\n%s", dot)
+	}
+
+	var tree TreeNode
+	jsonDump := &bytes.Buffer{}
+	if err := router.DumpTree(jsonDump, TreeJSON); err != nil {
+		t.Fatalf("router.DumpTree(TreeJSON): got error %s", err)
+	}
+
+	if err := json.Unmarshal(jsonDump.Bytes(), &tree); err != nil {
+		t.Fatalf("router.DumpTree(TreeJSON): got invalid JSON %s", err)
+	}
+
+	param := tree.Children[0].Children[0].Children[0]
+	if param.Path != "/users/:id" || param.Kind != "param" || len(param.Routes) != 2 || param.Routes[0].Hits != 1 {
+		t.Errorf("router.DumpTree(TreeJSON): got node %+v", param)
+	}
+}
+
+func TestRouterCountHits(t *testing.T) {
+	router := NewRouter()
+	route := router.Get("/users/:id", emptyHandler)
+
+	router.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/users/1", nil))
+
+	if hits := route.Hits(); hits != 0 {
+		t.Errorf("route.Hits(): got %d without router.CountHits, wanted 0", hits)
+	}
+
+	router.CountHits = true
+	router.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/users/1", nil))
+
+	if hits := route.Hits(); hits != 1 {
+		t.Errorf("route.Hits(): got %d, wanted 1", hits)
+	}
+}
@@ -0,0 +1,213 @@
+package hyper
+
+import (
+	"errors"
+	"fmt"
+	"html"
+	"io/fs"
+	"net/http"
+	"net/url"
+	"os"
+	"path"
+	"sort"
+	"strings"
+
+	"github.com/bencicandrej/hyper-router/params"
+)
+
+// FileOption configures the file server registered by router.ServeFiles.
+type FileOption func(*fileServer)
+
+// IndexFile sets the name of the file served for directories,
+// "index.html" by default.
+func IndexFile(name string) FileOption {
+	return func(server *fileServer) {
+		server.index = name
+	}
+}
+
+// DisableListing responds with 404 for directories without an index
+// file, instead of listing their contents.
+func DisableListing() FileOption {
+	return func(server *fileServer) {
+		server.listing = false
+	}
+}
+
+// SPA serves the index file of the root directory for every path
+// that does not exist, so a single page application can handle
+// its own routing.
+func SPA() FileOption {
+	return func(server *fileServer) {
+		server.spa = true
+	}
+}
+
+// ServeFiles serves files from the root file system on the path,
+// which must end with a wildcard, e.g. /static/*filepath. The wildcard
+// value is used as the name of the file.
+//
+// The files are served for the GET and HEAD methods, with the
+// Last-Modified and ETag headers, and support for Range requests.
+func (r *Router) ServeFiles(path string, root http.FileSystem, options ...FileOption) *RouteBuilder {
+	if path == "" || path[0] != '/' {
+		panic(fmt.Sprintf("path must start with '/' in '%s'", path))
+	}
+
+	wildcard, ok := nodeLabel(path).getWildcard()
+	if !ok || path[wildcard-1] != '/' {
+		panic(fmt.Sprintf("path must end with a wildcard in '%s'", path))
+	}
+
+	server := &fileServer{
+		root:    root,
+		prefix:  path[:wildcard],
+		param:   path[wildcard+1:],
+		index:   "index.html",
+		listing: true,
+	}
+
+	for _, option := range options {
+		option(server)
+	}
+
+	if server.fingerprinted {
+		manifest, err := buildManifest(root)
+		if err != nil {
+			panic(fmt.Sprintf("can not build the asset manifest for '%s': %s", path, err))
+		}
+
+		server.manifest = manifest
+		r.assets = append(r.assets, server)
+	}
+
+	return r.Route(path).Get(server).Head(server)
+}
+
+// ServeFS is a shortcut to the router.ServeFiles(path, http.FS(fsys), options...) method,
+// e.g. to serve the files of an embed.FS.
+func (r *Router) ServeFS(path string, fsys fs.FS, options ...FileOption) *RouteBuilder {
+	return r.ServeFiles(path, http.FS(fsys), options...)
+}
+
+type fileServer struct {
+	root    http.FileSystem
+	prefix  string
+	param   string
+	index   string
+	listing bool
+	spa     bool
+
+	precompressed bool
+	fingerprinted bool
+	manifest      *assetManifest
+}
+
+func (server *fileServer) ServeHTTP(w http.ResponseWriter, req *http.Request) {
+	ps, _ := params.FromContext(req.Context())
+	name, _ := ps.ByName(server.param)
+
+	// Cleaning the rooted name removes all '..' elements,
+	// so the name can not point outside of the root.
+	name = path.Clean("/" + name)
+
+	f, err := server.root.Open(name)
+	if err != nil {
+		server.serveError(w, req, err)
+		return
+	}
+	defer f.Close()
+
+	stat, err := f.Stat()
+	if err != nil {
+		server.serveError(w, req, err)
+		return
+	}
+
+	if stat.IsDir() {
+		// Redirect to the trailing slash, so the relative
+		// links in the directory resolve correctly.
+		if !strings.HasSuffix(req.URL.Path, "/") {
+			http.Redirect(w, req, path.Base(req.URL.Path)+"/", http.StatusMovedPermanently)
+			return
+		}
+
+		index, err := server.root.Open(path.Join(name, server.index))
+		if err == nil {
+			defer index.Close()
+
+			if indexStat, err := index.Stat(); err == nil && !indexStat.IsDir() {
+				serveFile(w, req, index, indexStat)
+				return
+			}
+		}
+
+		if !server.listing {
+			server.serveError(w, req, os.ErrNotExist)
+			return
+		}
+
+		listDir(w, f)
+		return
+	}
+
+	server.serveAsset(w, req, name, f, stat)
+}
+
+// serveError responds with the status matching the error, or with
+// the root index file if the file does not exist in the SPA mode.
+func (server *fileServer) serveError(w http.ResponseWriter, req *http.Request, err error) {
+	if errors.Is(err, fs.ErrNotExist) && server.spa {
+		if f, err := server.root.Open("/" + server.index); err == nil {
+			defer f.Close()
+
+			if stat, err := f.Stat(); err == nil && !stat.IsDir() {
+				serveFile(w, req, f, stat)
+				return
+			}
+		}
+	}
+
+	switch {
+	case errors.Is(err, fs.ErrNotExist):
+		http.NotFound(w, req)
+	case errors.Is(err, fs.ErrPermission):
+		http.Error(w, http.StatusText(http.StatusForbidden), http.StatusForbidden)
+	default:
+		http.Error(w, http.StatusText(http.StatusInternalServerError), http.StatusInternalServerError)
+	}
+}
+
+// serveFile serves the file content with a validator ETag
+// derived from the modification time and size of the file.
+func serveFile(w http.ResponseWriter, req *http.Request, f http.File, stat os.FileInfo) {
+	if w.Header().Get("ETag") == "" {
+		w.Header().Set("ETag", fmt.Sprintf(`"%x-%x"`, stat.ModTime().UnixNano(), stat.Size()))
+	}
+
+	http.ServeContent(w, req, stat.Name(), stat.ModTime(), f)
+}
+
+// listDir writes a simple HTML list of the directory contents.
+func listDir(w http.ResponseWriter, dir http.File) {
+	entries, err := dir.Readdir(-1)
+	if err != nil {
+		http.Error(w, "error reading directory", http.StatusInternalServerError)
+		return
+	}
+
+	sort.Slice(entries, func(i, j int) bool { return entries[i].Name() < entries[j].Name() })
+
+	w.Header().Set("Content-Type", "text/html; charset=utf-8")
+	fmt.Fprintln(w, "<pre>")
+	for _, entry := range entries {
+		name := entry.Name()
+		if entry.IsDir() {
+			name += "/"
+		}
+
+		link := url.URL{Path: name}
+		fmt.Fprintf(w, "<a href=\"%s\">%s</a>\n", link.String(), html.EscapeString(name))
+	}
+	fmt.Fprintln(w, "</pre>")
+}
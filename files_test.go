@@ -0,0 +1,102 @@
+package hyper
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"testing/fstest"
+)
+
+var testFiles = fstest.MapFS{
+	"index.html":         {Data: []byte("home")},
+	"css/site.css":       {Data: []byte("body {}")},
+	"docs/guide.txt":     {Data: []byte("guide")},
+	"private/index.html": {Data: []byte("private")},
+}
+
+func TestRouterServeFiles(t *testing.T) {
+	router := NewRouter()
+	router.ServeFS("/static/*filepath", testFiles)
+	router.ServeFS("/app/*filepath", testFiles, DisableListing(), SPA())
+	router.ServeFS("/files/*filepath", testFiles, DisableListing())
+
+	tests := []struct {
+		path string
+		code int
+		body string
+	}{
+		{"/static/", http.StatusOK, "home"},
+		{"/static/css/site.css", http.StatusOK, "body {}"},
+		{"/static/../css/site.css", http.StatusOK, "body {}"},
+		{"/static/docs/", http.StatusOK, "guide.txt"},
+		{"/static/docs", http.StatusMovedPermanently, ""},
+		{"/static/missing.txt", http.StatusNotFound, ""},
+		{"/files/docs/", http.StatusNotFound, ""},
+		{"/files/private/", http.StatusOK, "private"},
+		{"/app/users/1", http.StatusOK, "home"},
+	}
+
+	for _, test := range tests {
+		req := httptest.NewRequest(http.MethodGet, "/", nil)
+		req.URL.Path = test.path
+
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+
+		if w.Code != test.code {
+			t.Errorf("GET %s: got status %d, wanted %d", test.path, w.Code, test.code)
+		}
+
+		if test.code == http.StatusOK && !strings.Contains(w.Body.String(), test.body) {
+			t.Errorf("GET %s: got body '%s', wanted '%s'", test.path, w.Body.String(), test.body)
+		}
+	}
+}
+
+func TestRouterServeFilesConditional(t *testing.T) {
+	router := NewRouter()
+	router.ServeFS("/static/*filepath", testFiles)
+
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/static/docs/guide.txt", nil))
+
+	etag := w.Header().Get("ETag")
+	if etag == "" {
+		t.Fatal("GET /static/docs/guide.txt: got no ETag")
+	}
+
+	req := httptest.NewRequest(http.MethodGet, "/static/docs/guide.txt", nil)
+	req.Header.Set("If-None-Match", etag)
+
+	w = httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	if w.Code != http.StatusNotModified {
+		t.Errorf("GET /static/docs/guide.txt (If-None-Match): got status %d, wanted %d", w.Code, http.StatusNotModified)
+	}
+
+	req = httptest.NewRequest(http.MethodGet, "/static/docs/guide.txt", nil)
+	req.Header.Set("Range", "bytes=1-2")
+
+	w = httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	if w.Code != http.StatusPartialContent || w.Body.String() != "ui" {
+		t.Errorf("GET /static/docs/guide.txt (Range): got (%d, '%s'), wanted (%d, 'ui')", w.Code, w.Body.String(), http.StatusPartialContent)
+	}
+}
+
+func TestServeFilesInvalidPath(t *testing.T) {
+	for _, path := range []string{"*filepath", "/static", "/static*filepath"} {
+		func() {
+			defer func() {
+				if message, _ := recover().(string); !strings.HasPrefix(message, "path must") {
+					t.Errorf("router.ServeFiles('%s'): got panic %v, wanted a path error", path, message)
+				}
+			}()
+
+			NewRouter().ServeFiles(path, http.Dir("."))
+		}()
+	}
+}
@@ -0,0 +1,261 @@
+package hyper
+
+import (
+	"context"
+	"net/http"
+	"strings"
+
+	"github.com/bencicandrej/hyper-router/params"
+)
+
+// Freeze compiles the routes into an immutable structure optimized
+// for the lookup speed. It should be called after all routes are
+// registered, since registering a route on a frozen Router panics.
+//
+// Purely static routes are resolved with a single map lookup, and
+// the remaining ones with a flattened tree, in which the children
+// of a node are found through the index of their first bytes
+// instead of scanning the list of child nodes.
+func (r *Router) Freeze() {
+	if r.frozen != nil {
+		return
+	}
+
+	root := r.tree
+	if root == nil {
+		root = new(node)
+	}
+
+	for _, table := range r.versions {
+		table.Freeze()
+	}
+
+	r.frozen = compileTree(root)
+}
+
+// withParams appends the params to the params already stored in the
+// context, e.g. by the router this one is mounted under, the same
+// as params.NewContext does for the routers that are not frozen.
+func withParams(ctx context.Context, ps params.Params) context.Context {
+	if len(ps) == 0 {
+		return ctx
+	}
+
+	if outer, _ := params.FromContext(ctx); len(outer) > 0 {
+		ps = append(outer[:len(outer):len(outer)], ps...)
+	}
+
+	return params.WithParams(ctx, ps)
+}
+
+type nodeKind uint8
+
+const (
+	staticNode nodeKind = iota
+	parameterNode
+	wildcardNode
+)
+
+// frozenNode is a node of the frozen tree. Children of a node
+// are stored next to each other, starting at the first index.
+type frozenNode struct {
+	label    string
+	kind     nodeKind
+	handlers methodHandlers
+	methods  methodSet
+
+	// indices holds the first bytes of the static children, which are
+	// stored first, in the order of the indices. The variables parameter
+	// and wildcard children follow them, one per method at most.
+	indices   string
+	variables int32
+	first     int32
+}
+
+// frozenTree is a compiled, read-only version of the node tree.
+type frozenTree struct {
+	static map[string]methodHandlers
+	nodes  []frozenNode
+	// maxParams is the largest number of params a route of the
+	// tree can have, so the params are allocated only once.
+	maxParams int
+}
+
+// compileTree flattens the tree breadth-first,
+// so the children of every node are adjacent.
+func compileTree(root *node) *frozenTree {
+	tree := &frozenTree{
+		static: make(map[string]methodHandlers),
+	}
+
+	if root.isEmpty() {
+		return tree
+	}
+
+	type queued struct {
+		node   *node
+		path   string
+		params int
+	}
+
+	queue := []queued{{root, "", 0}}
+	tree.nodes = append(tree.nodes, frozenNode{})
+
+	for i := 0; i < len(queue); i++ {
+		n, nodeParams := queue[i].node, queue[i].params
+		path := queue[i].path + string(n.label)
+
+		compiled := frozenNode{
+			label:    string(n.label),
+			handlers: n.handlers,
+			methods:  n.methods,
+			first:    int32(len(queue)),
+		}
+
+		switch {
+		case n.isWildcard():
+			compiled.kind = wildcardNode
+			nodeParams++
+		case n.isParameter():
+			compiled.kind = parameterNode
+			nodeParams++
+		}
+
+		if compiled.handlers != nil {
+			if nodeParams == 0 {
+				tree.static[path] = compiled.handlers
+			}
+
+			if nodeParams > tree.maxParams {
+				tree.maxParams = nodeParams
+			}
+		}
+
+		var variables []*node
+		for _, child := range n.children {
+			if child.isWildcard() || child.isParameter() {
+				variables = append(variables, child)
+				continue
+			}
+
+			compiled.indices += string(child.label[0])
+			queue = append(queue, queued{child, path, nodeParams})
+			tree.nodes = append(tree.nodes, frozenNode{})
+		}
+
+		for _, child := range variables {
+			compiled.variables++
+			queue = append(queue, queued{child, path, nodeParams})
+			tree.nodes = append(tree.nodes, frozenNode{})
+		}
+
+		tree.nodes[i] = compiled
+	}
+
+	return tree
+}
+
+// lookup finds the handler of the method index for the path. It follows
+// the same rules as the node.getHandler method, but collects the params
+// in the order of their appearance in the path.
+func (tree *frozenTree) lookup(path string, method int) (http.Handler, params.Params) {
+	if handler := tree.static[path].get(method); handler != nil {
+		return handler, nil
+	}
+
+	if len(tree.nodes) == 0 {
+		return nil, nil
+	}
+
+	var ps params.Params
+	n := &tree.nodes[0]
+
+	for {
+		switch n.kind {
+		case wildcardNode:
+			return n.handlers.get(method), appendParam(ps, tree.maxParams, n.label[1:], path)
+
+		case parameterNode:
+			end := strings.IndexByte(path, '/')
+			if end == 0 {
+				return nil, nil
+			}
+
+			if end == -1 {
+				handler := n.handlers.get(method)
+				if handler == nil {
+					return nil, nil
+				}
+
+				return handler, appendParam(ps, tree.maxParams, n.label[1:], path)
+			}
+
+			ps = appendParam(ps, tree.maxParams, n.label[1:], path[:end])
+			path = path[end:]
+
+		default:
+			if !strings.HasPrefix(path, n.label) {
+				return nil, nil
+			}
+
+			if len(path) == len(n.label) {
+				if handler := n.handlers.get(method); handler != nil {
+					return handler, ps
+				}
+
+				// An empty catch-all is served by the wildcard child.
+				if n = tree.variable(n, method); n != nil && n.kind == wildcardNode {
+					return n.handlers.get(method), appendParam(ps, tree.maxParams, n.label[1:], "")
+				}
+
+				return nil, nil
+			}
+
+			path = path[len(n.label):]
+		}
+
+		n = tree.child(n, path, method)
+		if n == nil {
+			return nil, nil
+		}
+	}
+}
+
+// child returns the child of the node that supports
+// the path and leads to the routes of the method index.
+func (tree *frozenTree) child(n *frozenNode, path string, method int) *frozenNode {
+	if len(path) == 0 {
+		return nil
+	}
+
+	if i := strings.IndexByte(n.indices, path[0]); i != -1 {
+		if child := &tree.nodes[n.first+int32(i)]; child.methods.has(method) {
+			return child
+		}
+	}
+
+	return tree.variable(n, method)
+}
+
+// variable returns the parameter or wildcard child of
+// the node that leads to the routes of the method index.
+func (tree *frozenTree) variable(n *frozenNode, method int) *frozenNode {
+	first := n.first + int32(len(n.indices))
+	for i := first; i < first+n.variables; i++ {
+		if child := &tree.nodes[i]; child.methods.has(method) {
+			return child
+		}
+	}
+
+	return nil
+}
+
+// appendParam appends the param, allocating the params with
+// the capacity for all params of the tree on the first one.
+func appendParam(ps params.Params, capacity int, key, value string) params.Params {
+	if ps == nil {
+		ps = make(params.Params, 0, capacity)
+	}
+
+	return append(ps, params.Param{Key: key, Value: value})
+}
@@ -0,0 +1,125 @@
+package hyper
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/bencicandrej/hyper-router/params"
+)
+
+func TestFrozenRouterLookup(t *testing.T) {
+	routes := []string{
+		"/",
+		"/login",
+		"/logout",
+		"/api/v1",
+		"/api/v1/foo/bar",
+		"/api/v1/users",
+		"/api/v1/users/:id",
+		"/api/v1/users/:id/sites",
+		"/api/v1/users/:id/sites/*url",
+		"/api/v1/usecases/:type/:id",
+		"/static/*filepath",
+	}
+
+	paths := []string{
+		"/",
+		"/login",
+		"/logi",
+		"/logout/",
+		"/api/v1",
+		"/api/v1/",
+		"/api/v1/foo/bar",
+		"/api/v1/users",
+		"/api/v1/users/",
+		"/api/v1/users/42",
+		"/api/v1/users/42/",
+		"/api/v1/users/42/sites",
+		"/api/v1/users/42/sites/",
+		"/api/v1/users/42/sites/example.com/foo",
+		"/api/v1/usecases/a/b",
+		"/api/v1/usecases/a",
+		"/static/",
+		"/static/css/site.css",
+		"/missing",
+	}
+
+	router := NewRouter()
+	frozen := NewRouter()
+	for _, route := range routes {
+		router.Get(route, emptyHandler)
+		frozen.Get(route, emptyHandler)
+	}
+	frozen.Freeze()
+
+	for _, path := range paths {
+		want, wantCtx := router.getRoute(context.Background(), http.MethodGet, path)
+		got, gotCtx := frozen.getRoute(context.Background(), http.MethodGet, path)
+
+		if (want == nil) != (got == nil) || (want != nil && want.Pattern != got.Pattern) {
+			t.Errorf("frozen.getRoute('%s'): got %v, wanted %v", path, got, want)
+			continue
+		}
+
+		wantParams, _ := params.FromContext(wantCtx)
+		gotParams, _ := params.FromContext(gotCtx)
+		for _, param := range wantParams {
+			if value, _ := gotParams.ByName(param.Key); value != param.Value {
+				t.Errorf("frozen.getRoute('%s'): got param %s='%s', wanted '%s'", path, param.Key, value, param.Value)
+			}
+		}
+	}
+}
+
+func TestFrozenRouterRegistration(t *testing.T) {
+	router := NewRouter()
+	router.Get("/foo", emptyHandler)
+	router.Freeze()
+
+	defer func() {
+		if recover() == nil {
+			t.Error("router.Get() on a frozen router: expected panic, got none")
+		}
+	}()
+
+	router.Get("/bar", emptyHandler)
+}
+
+func TestFrozenRouterNested(t *testing.T) {
+	modes := map[string]func(r *Router){
+		"default": func(r *Router) {},
+		"frozen":  func(r *Router) { r.Freeze() },
+		"cached":  func(r *Router) { r.EnableCache(10) },
+	}
+
+	for name, mode := range modes {
+		inner := NewRouter()
+		inner.Get("/a/:x", http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			ps, _ := params.FromContext(r.Context())
+			fmt.Fprint(w, ps)
+		}))
+		mode(inner)
+
+		outer := NewRouter()
+		outer.Get("/t/:tenant/*rest", http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			ps, _ := params.FromContext(r.Context())
+			rest, _ := ps.ByName("rest")
+
+			r.URL.Path = "/" + rest
+			inner.ServeHTTP(w, r)
+		}))
+		mode(outer)
+
+		for _, tenant := range []string{"t1", "t2"} {
+			w := httptest.NewRecorder()
+			outer.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/t/"+tenant+"/a/7", nil))
+
+			if want := "[{tenant " + tenant + "} {rest a/7} {x 7}]"; w.Body.String() != want {
+				t.Errorf("%s: GET /t/%s/a/7: got params %s, wanted %s", name, tenant, w.Body.String(), want)
+			}
+		}
+	}
+}
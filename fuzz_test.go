@@ -0,0 +1,320 @@
+package hyper
+
+import (
+	"context"
+	"fmt"
+	"math/rand"
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/bencicandrej/hyper-router/params"
+)
+
+// conflictErrors are the documented reasons for node.insert to panic.
+var conflictErrors = []string{
+	"already exists",
+	"must start with '/'",
+	"wildcard parameter must be the last element",
+}
+
+// patternHandler identifies the route the handler was registered for.
+type patternHandler string
+
+func (patternHandler) ServeHTTP(http.ResponseWriter, *http.Request) {}
+
+// referenceMatch is a naive, linear implementation of the route matching:
+// a static text must match exactly, a parameter matches a non-empty
+// segment up to the next '/' and a wildcard matches the rest of the path.
+func referenceMatch(pattern, path string) (params.Params, bool) {
+	var ps params.Params
+
+	for pattern != "" {
+		switch pattern[0] {
+		case ':':
+			end := strings.IndexByte(pattern, '/')
+			if end == -1 {
+				end = len(pattern)
+			}
+
+			valueEnd := strings.IndexByte(path, '/')
+			if valueEnd == -1 {
+				valueEnd = len(path)
+			}
+
+			if valueEnd == 0 {
+				return nil, false
+			}
+
+			ps = append(ps, params.Param{Key: pattern[1:end], Value: path[:valueEnd]})
+			pattern, path = pattern[end:], path[valueEnd:]
+
+		case '*':
+			return append(ps, params.Param{Key: pattern[1:], Value: path}), true
+
+		default:
+			end := strings.IndexAny(pattern, ":*")
+			if end == -1 {
+				end = len(pattern)
+			}
+
+			if !strings.HasPrefix(path, pattern[:end]) {
+				return nil, false
+			}
+
+			pattern, path = pattern[end:], path[end:]
+		}
+	}
+
+	return ps, path == ""
+}
+
+// checkTree inserts the routes into a tree, each for the method index
+// of the same position, or 0 if there are no methods, and compares the
+// lookups of the paths with the reference matcher, for every method.
+func checkTree(t *testing.T, routes []string, methods []int, paths []string) {
+	tree := &node{}
+	inserted := make(map[int][]string)
+
+	for i, route := range routes {
+		method := 0
+		if methods != nil {
+			method = methods[i]
+		}
+
+		ok := insertRoute(t, tree, route, method)
+
+		// A route conflicts only with the routes of its own method, and a
+		// failed insertion leaves the tree untouched, so a tree of the
+		// routes of the method inserted so far agrees on the outcome.
+		fresh := &node{}
+		for _, previous := range inserted[method] {
+			fresh.insert(nodeLabel(previous), method, patternHandler(previous))
+		}
+
+		if insertRoute(t, fresh, route, method) != ok {
+			t.Fatalf("node.insert('%s', %d): got ok=%v, wanted %v for a tree of the inserted routes %q", route, method, ok, !ok, inserted[method])
+		}
+
+		if ok {
+			inserted[method] = append(inserted[method], route)
+		}
+	}
+
+	frozen := compileTree(tree)
+
+	for method, routes := range inserted {
+		for _, path := range paths {
+			matches := make(map[string]params.Params)
+			for _, route := range routes {
+				if ps, ok := referenceMatch(route, path); ok {
+					matches[route] = ps
+				}
+			}
+
+			handler, ctx := tree.getHandler(context.Background(), nodeLabel(path), method)
+			ps, _ := params.FromContext(ctx)
+			compareMatch(t, "node.getHandler", routes, path, handler, ps, matches)
+
+			frozenHandler, frozenParams := frozen.lookup(path, method)
+			compareMatch(t, "frozenTree.lookup", routes, path, frozenHandler, frozenParams, matches)
+		}
+	}
+}
+
+// insertRoute inserts the route, and fails the test if the
+// insertion panics for a reason other than a documented conflict.
+func insertRoute(t *testing.T, tree *node, route string, method int) (ok bool) {
+	defer func() {
+		if r := recover(); r != nil {
+			ok = false
+
+			message := fmt.Sprint(r)
+			for _, conflict := range conflictErrors {
+				if strings.Contains(message, conflict) {
+					return
+				}
+			}
+
+			t.Fatalf("node.insert('%s'): unexpected panic: %s", route, message)
+		}
+	}()
+
+	tree.insert(nodeLabel(route), method, patternHandler(route))
+
+	return true
+}
+
+func compareMatch(t *testing.T, lookup string, routes []string, path string, handler http.Handler, ps params.Params, matches map[string]params.Params) {
+	if handler == nil {
+		if len(matches) > 0 {
+			t.Fatalf("%s('%s'): got no match, wanted one of %v (routes %q)", lookup, path, matches, routes)
+		}
+
+		return
+	}
+
+	route := string(handler.(patternHandler))
+	want, ok := matches[route]
+	if !ok {
+		t.Fatalf("%s('%s'): got route '%s', wanted one of %v (routes %q)", lookup, path, route, matches, routes)
+	}
+
+	if fmt.Sprint(ps) != fmt.Sprint(want) {
+		t.Fatalf("%s('%s'): got params %v, wanted %v (routes %q)", lookup, path, ps, want, routes)
+	}
+}
+
+// routeTokens are the building blocks of the generated routes and paths.
+var routeTokens = []string{"/", "/", "/", "a", "b", "ab", "users", ":id", ":name", "*rest", "*url", ":", "*", "-"}
+
+// pathTokens are the building blocks of the generated request paths.
+var pathTokens = []string{"/", "/", "a", "b", "ab", "users", "42", "x", ""}
+
+// generate builds a string from the tokens selected by the data.
+func generate(data []byte, tokens []string) string {
+	var b strings.Builder
+	for _, d := range data {
+		b.WriteString(tokens[int(d)%len(tokens)])
+	}
+
+	return b.String()
+}
+
+// generateRoutes splits the data into the route set, the method indices
+// of the routes and the paths. Paths are generated both from the tokens
+// and from the routes, with their variables replaced, so most of the
+// routes are actually matched.
+func generateRoutes(data []byte) (routes []string, methods []int, paths []string) {
+	for _, chunk := range strings.Split(string(data), "\x00") {
+		if len(chunk) == 0 {
+			continue
+		}
+
+		route := generate([]byte(chunk), routeTokens)
+		if chunk[0]%2 == 0 {
+			route = "/" + route
+		}
+
+		routes = append(routes, route)
+		methods = append(methods, int(chunk[0]>>1)%3)
+		paths = append(paths,
+			generate([]byte(chunk), pathTokens),
+			"/"+generate([]byte(chunk), pathTokens),
+			samplePath(route, chunk),
+		)
+	}
+
+	return routes, methods, paths
+}
+
+// samplePath replaces the variables of the route with values.
+func samplePath(route, seed string) string {
+	values := []string{"42", "x", "a/b", ""}
+
+	var b strings.Builder
+	for i := 0; i < len(route); i++ {
+		if route[i] != ':' && route[i] != '*' {
+			b.WriteByte(route[i])
+			continue
+		}
+
+		end := strings.IndexByte(route[i:], '/')
+		if end == -1 {
+			end = len(route) - i
+		}
+
+		value := values[(i+len(seed))%len(values)]
+		if route[i] == ':' {
+			value = strings.ReplaceAll(value, "/", "")
+		}
+
+		b.WriteString(value)
+		i += end - 1
+	}
+
+	return b.String()
+}
+
+func FuzzTree(f *testing.F) {
+	f.Add([]byte("\x05\x00\x02\x07\x00\x02\x07\x00\x08"))
+	f.Add([]byte("\x06\x07\x00\x06\x07\x02\x00\x02\x09"))
+	f.Add([]byte("\x02\x03\x00\x02\x03\x00\x02\x08\x00\x02\x0a"))
+	f.Add([]byte("\x00\x03\x00\x00\x0b\x00\x00\x0c"))
+
+	f.Fuzz(func(t *testing.T, data []byte) {
+		routes, methods, paths := generateRoutes(data)
+		checkTree(t, routes, methods, paths)
+	})
+}
+
+// TestTreeReference runs the same checks as FuzzTree
+// on random route sets, with a fixed seed.
+func TestTreeReference(t *testing.T) {
+	random := rand.New(rand.NewSource(1))
+
+	for i := 0; i < 2000; i++ {
+		data := make([]byte, 4+random.Intn(40))
+		random.Read(data)
+
+		for j := range data {
+			if random.Intn(6) == 0 {
+				data[j] = 0
+			}
+		}
+
+		routes, methods, paths := generateRoutes(data)
+		checkTree(t, routes, methods, paths)
+	}
+}
+
+func TestTreeReferenceCases(t *testing.T) {
+	tests := []struct {
+		routes  []string
+		methods []int
+		paths   []string
+	}{
+		{
+			// Empty catch-all.
+			routes: []string{"/static/*filepath"},
+			paths:  []string{"/static/", "/static", "/static/css/site.css"},
+		},
+		{
+			// Params are ordered the same as in the path.
+			routes: []string{"/users/:id/sites/:site/*url"},
+			paths:  []string{"/users/1/sites/2/3/4", "/users/1/sites/2/"},
+		},
+		{
+			// Params never match an empty segment.
+			routes: []string{"/users/:id/sites"},
+			paths:  []string{"/users//sites", "/users/1/sites"},
+		},
+		{
+			// Wildcard names run to the end of the route.
+			routes: []string{"/files/*path:name"},
+			paths:  []string{"/files/a/b", "/files/"},
+		},
+		{
+			// Static and param siblings of different methods.
+			routes:  []string{"/users/:id", "/users/search", "/users/:id/sites"},
+			methods: []int{1, 3, 3},
+			paths:   []string{"/users/search", "/users/42", "/users/search/sites"},
+		},
+		{
+			// Params of different methods at the same position.
+			routes:  []string{"/users/:id", "/users/:uid", "/users/:id/sites"},
+			methods: []int{1, 4, 4},
+			paths:   []string{"/users/42", "/users/42/sites"},
+		},
+		{
+			// Wildcard and empty catch-all of different methods.
+			routes:  []string{"/files/*path", "/files/", "/files/new", "/files/:name/raw"},
+			methods: []int{1, 3, 4, 3},
+			paths:   []string{"/files/", "/files/new", "/files/a/b", "/files/a/raw"},
+		},
+	}
+
+	for _, test := range tests {
+		checkTree(t, test.routes, test.methods, test.paths)
+	}
+}
@@ -0,0 +1,21 @@
+// Package inflect converts the English words of the route paths,
+// so the names derived from the same path agree across the packages.
+package inflect
+
+import "strings"
+
+// Singular returns the singular of the English plural, for the regular
+// plurals only, e.g. category for categories and box for boxes.
+func Singular(word string) string {
+	switch {
+	case strings.HasSuffix(word, "ies") && len(word) > 3:
+		return word[:len(word)-3] + "y"
+	case strings.HasSuffix(word, "sses"), strings.HasSuffix(word, "xes"), strings.HasSuffix(word, "zes"),
+		strings.HasSuffix(word, "ches"), strings.HasSuffix(word, "shes"):
+		return word[:len(word)-2]
+	case strings.HasSuffix(word, "ss"):
+		return word
+	}
+
+	return strings.TrimSuffix(word, "s")
+}
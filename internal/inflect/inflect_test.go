@@ -0,0 +1,20 @@
+package inflect
+
+import "testing"
+
+func TestSingular(t *testing.T) {
+	tests := map[string]string{
+		"comments":   "comment",
+		"categories": "category",
+		"boxes":      "box",
+		"addresses":  "address",
+		"branches":   "branch",
+		"access":     "access",
+	}
+
+	for plural, want := range tests {
+		if got := Singular(plural); got != want {
+			t.Errorf("Singular('%s'): got '%s', wanted '%s'", plural, got, want)
+		}
+	}
+}
@@ -0,0 +1,97 @@
+package hyper
+
+import (
+	"context"
+	"net/http"
+	"path"
+	"strings"
+
+	"github.com/bencicandrej/hyper-router/params"
+)
+
+// Match is the result of a route lookup.
+type Match struct {
+	// Route is the matched route, and Handler is its handler,
+	// wrapped in the route middleware.
+	Route   *Route
+	Handler http.Handler
+
+	// Pattern and Name are the pattern and the name of the matched route.
+	Pattern string
+	Name    string
+
+	// Params are the params matched in the path.
+	Params params.Params
+
+	// Allowed lists the methods that have a route for the path,
+	// when the path matched but the method did not.
+	Allowed []string
+	// Redirect is the path that would match the method, when the path
+	// did not match because of a trailing slash or an unclean path.
+	Redirect string
+}
+
+// Lookup resolves the route for the method and path without serving
+// the request, and reports whether a route was found.
+//
+// On a miss, the Match holds the allowed methods of the path,
+// or the redirect suggestion, if there are any.
+//
+// Lookup only resolves the routes of the router itself, the routes
+// of a version are resolved by router.Version(n).Lookup. The route
+// matchers depend on the request, so they are not evaluated.
+func (r *Router) Lookup(method, path string) (Match, bool) {
+	route, ctx := r.getRoute(context.Background(), method, path)
+	if route == nil {
+		return Match{
+			Allowed:  r.allowed(path),
+			Redirect: r.redirectFor(method, path),
+		}, false
+	}
+
+	ps, _ := params.FromContext(ctx)
+
+	return Match{
+		Route:   route,
+		Handler: route.handler,
+		Pattern: route.Pattern,
+		Name:    route.Name,
+		Params:  ps,
+	}, true
+}
+
+// redirectFor returns the cleaned path, or the path with the
+// trailing slash added or removed, if it matches a route.
+func (r *Router) redirectFor(method, p string) string {
+	var candidates []string
+
+	if p != "" && p[0] == '/' {
+		if clean := cleanPath(p); clean != p {
+			candidates = append(candidates, clean)
+		}
+	}
+
+	if len(p) > 1 && strings.HasSuffix(p, "/") {
+		candidates = append(candidates, p[:len(p)-1])
+	} else {
+		candidates = append(candidates, p+"/")
+	}
+
+	for _, candidate := range candidates {
+		if route, _ := r.getRoute(context.Background(), method, candidate); route != nil {
+			return candidate
+		}
+	}
+
+	return ""
+}
+
+// cleanPath is path.Clean that keeps the trailing slash.
+func cleanPath(p string) string {
+	clean := path.Clean(p)
+	if strings.HasSuffix(p, "/") && clean != "/" {
+		clean += "/"
+	}
+
+	return clean
+}
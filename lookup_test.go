@@ -0,0 +1,55 @@
+package hyper
+
+import (
+	"net/http"
+	"reflect"
+	"testing"
+)
+
+func TestLookup(t *testing.T) {
+	router := NewRouter()
+	router.CountHits = true
+	router.Get("/users/:id", methodHandler("user")).Named("user")
+	router.Delete("/users/:id", methodHandler("delete"))
+	router.Get("/sites/", methodHandler("sites"))
+	router.Get("/about", methodHandler("about"))
+
+	match, ok := router.Lookup(http.MethodGet, "/users/42")
+	if !ok || match.Pattern != "/users/:id" || match.Name != "user" || match.Handler == nil {
+		t.Fatalf("Lookup(GET, /users/42): got %+v, %v", match, ok)
+	}
+
+	if id, _ := match.Params.ByName("id"); id != "42" {
+		t.Errorf("Lookup(GET, /users/42): got param id=%q, wanted 42", id)
+	}
+
+	tests := []struct {
+		method   string
+		path     string
+		allowed  []string
+		redirect string
+	}{
+		{http.MethodPost, "/users/42", []string{http.MethodDelete, http.MethodGet}, ""},
+		{http.MethodGet, "/sites", nil, "/sites/"},
+		{http.MethodGet, "/about/", nil, "/about"},
+		{http.MethodGet, "/users/../about", nil, "/about"},
+		{http.MethodGet, "/missing", nil, ""},
+	}
+
+	for _, test := range tests {
+		match, ok := router.Lookup(test.method, test.path)
+		if ok {
+			t.Errorf("Lookup(%s, %s): got match %s", test.method, test.path, match.Pattern)
+			continue
+		}
+
+		if !reflect.DeepEqual(match.Allowed, test.allowed) || match.Redirect != test.redirect {
+			t.Errorf("Lookup(%s, %s): got allowed %v and redirect %q, wanted %v and %q",
+				test.method, test.path, match.Allowed, match.Redirect, test.allowed, test.redirect)
+		}
+	}
+
+	if router.Routes()[0].Hits() != 0 {
+		t.Errorf("Lookup(): counted a hit")
+	}
+}
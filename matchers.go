@@ -0,0 +1,100 @@
+package hyper
+
+import (
+	"net/http"
+	"strings"
+)
+
+// PathPrefix matches the requests with the path starting with the prefix,
+// on a segment boundary: /health matches /health and /health/db, but not /healthz.
+func PathPrefix(prefix string) Matcher {
+	prefix = strings.TrimSuffix(prefix, "/")
+
+	return func(req *http.Request) bool {
+		path := req.URL.Path
+
+		return path == prefix || strings.HasPrefix(path, prefix+"/")
+	}
+}
+
+// Methods matches the requests with any of the methods.
+func Methods(methods ...string) Matcher {
+	return func(req *http.Request) bool {
+		for _, method := range methods {
+			if req.Method == method {
+				return true
+			}
+		}
+
+		return false
+	}
+}
+
+// Header matches the requests with the header set to the value,
+// or, if the value is empty, with the header present.
+func Header(key, value string) Matcher {
+	return func(req *http.Request) bool {
+		values, ok := req.Header[http.CanonicalHeaderKey(key)]
+		if !ok || value == "" {
+			return ok
+		}
+
+		for _, v := range values {
+			if v == value {
+				return true
+			}
+		}
+
+		return false
+	}
+}
+
+// RouteName matches the requests served by a route with any of the names.
+//
+// The route is known only to the route middleware, so the matcher
+// never matches the requests before they reach the router.
+func RouteName(names ...string) Matcher {
+	return func(req *http.Request) bool {
+		route, ok := RouteFromContext(req.Context())
+		if !ok {
+			return false
+		}
+
+		for _, name := range names {
+			if route.Name == name {
+				return true
+			}
+		}
+
+		return false
+	}
+}
+
+// RouteTag matches the requests served by a route with the operation tag,
+// see route.WithTags.
+//
+// The route is known only to the route middleware, so the matcher
+// never matches the requests before they reach the router.
+func RouteTag(tag string) Matcher {
+	return func(req *http.Request) bool {
+		route, ok := RouteFromContext(req.Context())
+		if !ok {
+			return false
+		}
+
+		for _, t := range route.Operation.Tags {
+			if t == tag {
+				return true
+			}
+		}
+
+		return false
+	}
+}
+
+// Not matches the requests that do not satisfy the matcher.
+func Not(matcher Matcher) Matcher {
+	return func(req *http.Request) bool {
+		return !matcher(req)
+	}
+}
@@ -0,0 +1,77 @@
+package hyper
+
+import "net/http"
+
+// standardMethods holds the methods with a fixed index in the
+// handler tables, so they are resolved without a map lookup.
+// The index 0 is reserved for MethodAny.
+var standardMethods = []string{
+	MethodAny,
+	http.MethodGet,
+	http.MethodHead,
+	http.MethodPost,
+	http.MethodPut,
+	http.MethodPatch,
+	http.MethodDelete,
+	http.MethodOptions,
+	http.MethodConnect,
+	http.MethodTrace,
+}
+
+// methodIndex returns the index of the method in the handler tables.
+// Custom methods are indexed in the order of their registration.
+func (r *Router) methodIndex(method string) (int, bool) {
+	switch method {
+	case MethodAny:
+		return 0, true
+	case http.MethodGet:
+		return 1, true
+	case http.MethodHead:
+		return 2, true
+	case http.MethodPost:
+		return 3, true
+	case http.MethodPut:
+		return 4, true
+	case http.MethodPatch:
+		return 5, true
+	case http.MethodDelete:
+		return 6, true
+	case http.MethodOptions:
+		return 7, true
+	case http.MethodConnect:
+		return 8, true
+	case http.MethodTrace:
+		return 9, true
+	}
+
+	index, ok := r.customMethods[method]
+
+	return index, ok
+}
+
+// addMethod returns the index of the method, indexing it first
+// if it is a custom method seen for the first time.
+func (r *Router) addMethod(method string) int {
+	if index, ok := r.methodIndex(method); ok {
+		return index
+	}
+
+	if r.customMethods == nil {
+		r.customMethods = make(map[string]int)
+	}
+
+	index := len(standardMethods) + len(r.methodNames)
+	r.customMethods[method] = index
+	r.methodNames = append(r.methodNames, method)
+
+	return index
+}
+
+// methodName returns the method of the handler table index.
+func (r *Router) methodName(index int) string {
+	if index < len(standardMethods) {
+		return standardMethods[index]
+	}
+
+	return r.methodNames[index-len(standardMethods)]
+}
@@ -56,3 +56,33 @@ func (stack MiddlewareStack) Append(middleware ...Middleware) MiddlewareStack {
 func (stack MiddlewareStack) Extend(newStack MiddlewareStack) MiddlewareStack {
 	return stack.Append(newStack.middleware...)
 }
+
+// When extends a stack, adding the specified middleware as the last
+// ones in the request flow, applied only to the requests that satisfy
+// the matcher. The other requests skip the middleware.
+//
+// When returns a new stack, leaving the original one untouched.
+func (stack MiddlewareStack) When(matcher Matcher, middleware ...Middleware) MiddlewareStack {
+	conditional := NewStack(middleware...)
+
+	return stack.Append(func(next http.Handler) http.Handler {
+		h := conditional.Do(next)
+
+		return http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+			if matcher(req) {
+				h.ServeHTTP(w, req)
+				return
+			}
+
+			next.ServeHTTP(w, req)
+		})
+	})
+}
+
+// Unless is the opposite of When, the middleware is applied
+// only to the requests that do not satisfy the matcher.
+//
+// Unless returns a new stack, leaving the original one untouched.
+func (stack MiddlewareStack) Unless(matcher Matcher, middleware ...Middleware) MiddlewareStack {
+	return stack.When(Not(matcher), middleware...)
+}
@@ -0,0 +1,95 @@
+package hyper
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func headerMiddleware(value string) Middleware {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.Header().Add("X-Middleware", value)
+			next.ServeHTTP(w, r)
+		})
+	}
+}
+
+func TestMiddlewareStackWhen(t *testing.T) {
+	stack := NewStack(headerMiddleware("logger")).
+		Unless(PathPrefix("/health"), headerMiddleware("auth")).
+		When(Methods(http.MethodGet, http.MethodHead), headerMiddleware("cache"), headerMiddleware("etag")).
+		When(Header("Accept-Encoding", ""), headerMiddleware("gzip"))
+
+	handler := stack.Do(methodHandler("ok"))
+
+	tests := []struct {
+		method   string
+		path     string
+		encoding string
+		expected string
+	}{
+		{http.MethodGet, "/users", "", "logger,auth,cache,etag"},
+		{http.MethodPost, "/users", "br", "logger,auth,gzip"},
+		{http.MethodGet, "/health", "", "logger,cache,etag"},
+		{http.MethodDelete, "/health/db", "", "logger"},
+		{http.MethodDelete, "/healthz", "", "logger,auth"},
+	}
+
+	for _, test := range tests {
+		req := httptest.NewRequest(test.method, test.path, nil)
+		if test.encoding != "" {
+			req.Header.Set("Accept-Encoding", test.encoding)
+		}
+
+		w := httptest.NewRecorder()
+		handler.ServeHTTP(w, req)
+
+		if got := strings.Join(w.Header()["X-Middleware"], ","); got != test.expected || w.Body.String() != "ok" {
+			t.Errorf("%s %s: got middleware %s, wanted %s", test.method, test.path, got, test.expected)
+		}
+	}
+}
+
+func TestMiddlewareStackWhenRoute(t *testing.T) {
+	stack := NewStack().
+		When(RouteName("users.show"), headerMiddleware("named")).
+		Unless(RouteTag("streaming"), headerMiddleware("gzip"))
+
+	router := NewRouter()
+	router.Get("/users/:id", stack.Do(methodHandler("user"))).Named("users.show")
+	router.Get("/events", stack.Do(methodHandler("events"))).WithTags("streaming")
+
+	tests := map[string]string{
+		"/users/1": "named,gzip",
+		"/events":  "",
+	}
+
+	for path, expected := range tests {
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, httptest.NewRequest(http.MethodGet, path, nil))
+
+		if got := strings.Join(w.Header()["X-Middleware"], ","); got != expected {
+			t.Errorf("GET %s: got middleware %q, wanted %q", path, got, expected)
+		}
+	}
+
+	// Outside of the router the route is not known.
+	w := httptest.NewRecorder()
+	stack.Do(methodHandler("ok")).ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/users/1", nil))
+
+	if got := strings.Join(w.Header()["X-Middleware"], ","); got != "gzip" {
+		t.Errorf("GET /users/1: got middleware %q, wanted gzip", got)
+	}
+}
+
+func TestHeaderMatcher(t *testing.T) {
+	req := httptest.NewRequest(http.MethodGet, "/", nil)
+	req.Header.Add("X-Version", "1")
+	req.Header.Add("X-Version", "2")
+
+	if !Header("x-version", "2")(req) || Header("X-Version", "3")(req) || Header("X-Other", "")(req) {
+		t.Errorf("Header(): got wrong matches for %v", req.Header)
+	}
+}
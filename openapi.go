@@ -0,0 +1,460 @@
+package hyper
+
+import (
+	"encoding/json"
+	"net/http"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Operation describes a route in the OpenAPI document.
+type Operation struct {
+	Summary     string
+	Description string
+	Tags        []string
+	Deprecated  bool
+
+	// Request is a value of the request body type, e.g. CreateUser{}.
+	Request interface{}
+	// Responses maps the status codes to the values of the response
+	// body types. A nil value describes a response without a body.
+	Responses map[int]interface{}
+}
+
+// WithSummary sets the summary and the optional description of the route operation.
+func (route *Route) WithSummary(summary string, description ...string) *Route {
+	route.Operation.Summary = summary
+	route.Operation.Description = strings.Join(description, "\n\n")
+
+	return route
+}
+
+// WithTags adds tags to the route operation.
+func (route *Route) WithTags(tags ...string) *Route {
+	route.Operation.Tags = append(route.Operation.Tags, tags...)
+
+	return route
+}
+
+// WithRequest sets the request body type of the route operation, from a value of the type.
+func (route *Route) WithRequest(body interface{}) *Route {
+	route.Operation.Request = body
+
+	return route
+}
+
+// WithResponse adds a response of the route operation, with the
+// body type taken from the value, or without a body if it is nil.
+func (route *Route) WithResponse(code int, body interface{}) *Route {
+	if route.Operation.Responses == nil {
+		route.Operation.Responses = make(map[int]interface{})
+	}
+
+	route.Operation.Responses[code] = body
+
+	return route
+}
+
+// OpenAPIInfo is the info object of the OpenAPI document.
+type OpenAPIInfo struct {
+	Title       string `json:"title"`
+	Version     string `json:"version"`
+	Description string `json:"description,omitempty"`
+}
+
+// OpenAPIDocument is an OpenAPI 3.1 document describing the routes.
+type OpenAPIDocument struct {
+	OpenAPI    string                       `json:"openapi"`
+	Info       OpenAPIInfo                  `json:"info"`
+	Paths      map[string]map[string]*apiOp `json:"paths"`
+	Components *apiComponents               `json:"components,omitempty"`
+}
+
+type apiOp struct {
+	OperationID string                  `json:"operationId,omitempty"`
+	Summary     string                  `json:"summary,omitempty"`
+	Description string                  `json:"description,omitempty"`
+	Tags        []string                `json:"tags,omitempty"`
+	Deprecated  bool                    `json:"deprecated,omitempty"`
+	Parameters  []apiParameter          `json:"parameters,omitempty"`
+	RequestBody *apiBody                `json:"requestBody,omitempty"`
+	Responses   map[string]*apiResponse `json:"responses,omitempty"`
+}
+
+type apiParameter struct {
+	Name        string     `json:"name"`
+	In          string     `json:"in"`
+	Description string     `json:"description,omitempty"`
+	Required    bool       `json:"required"`
+	Schema      *apiSchema `json:"schema"`
+}
+
+type apiBody struct {
+	Required bool                `json:"required,omitempty"`
+	Content  map[string]apiMedia `json:"content"`
+}
+
+type apiResponse struct {
+	Description string              `json:"description"`
+	Content     map[string]apiMedia `json:"content,omitempty"`
+}
+
+type apiMedia struct {
+	Schema *apiSchema `json:"schema"`
+}
+
+type apiComponents struct {
+	Schemas map[string]*apiSchema `json:"schemas"`
+}
+
+type apiSchema struct {
+	Ref                  string                `json:"$ref,omitempty"`
+	Type                 string                `json:"type,omitempty"`
+	Format               string                `json:"format,omitempty"`
+	Items                *apiSchema            `json:"items,omitempty"`
+	Properties           map[string]*apiSchema `json:"properties,omitempty"`
+	Required             []string              `json:"required,omitempty"`
+	AdditionalProperties *apiSchema            `json:"additionalProperties,omitempty"`
+}
+
+// openAPIMethods are the methods an OpenAPI path item can describe.
+var openAPIMethods = map[string]bool{
+	http.MethodGet:     true,
+	http.MethodHead:    true,
+	http.MethodPost:    true,
+	http.MethodPut:     true,
+	http.MethodPatch:   true,
+	http.MethodDelete:  true,
+	http.MethodOptions: true,
+	http.MethodTrace:   true,
+}
+
+// OpenAPI generates an OpenAPI 3.1 document from the registered routes.
+//
+// The path params of the routes become the {param} templates of the
+// paths, and the body schemas are derived from the Go types of the
+// route Operation, by the same rules encoding/json uses. Named struct
+// types are described once, in the schema components, under their
+// package path and name, e.g. example.com_app_models.User.
+//
+// The route names are the operationIds. Routes sharing a name, e.g. the
+// methods of a RouteBuilder, get the method appended, e.g. user.get.
+//
+// Routes registered for MethodAny or for the methods OpenAPI can not
+// describe, e.g. PURGE, are left out, as are the routes of the versions.
+func (r *Router) OpenAPI(info OpenAPIInfo) *OpenAPIDocument {
+	doc := &OpenAPIDocument{
+		OpenAPI: "3.1.0",
+		Info:    info,
+		Paths:   make(map[string]map[string]*apiOp),
+	}
+
+	schemas := &schemaBuilder{
+		schemas: make(map[string]*apiSchema),
+		names:   make(map[reflect.Type]string),
+	}
+
+	var routes []*Route
+	for _, route := range r.Routes() {
+		if openAPIMethods[route.Method] {
+			routes = append(routes, route)
+		}
+	}
+
+	operationIDs := operationIDs(routes)
+
+	for _, route := range routes {
+		path, parameters := openAPIPath(route.Pattern)
+
+		op := &apiOp{
+			OperationID: operationIDs[route],
+			Summary:     route.Operation.Summary,
+			Description: route.Operation.Description,
+			Tags:        route.Operation.Tags,
+			Deprecated:  route.Operation.Deprecated,
+			Parameters:  parameters,
+		}
+
+		if route.Operation.Request != nil {
+			op.RequestBody = &apiBody{
+				Required: true,
+				Content:  jsonContent(schemas.schema(reflect.TypeOf(route.Operation.Request))),
+			}
+		}
+
+		if len(route.Operation.Responses) > 0 {
+			op.Responses = make(map[string]*apiResponse)
+		}
+
+		for code, body := range route.Operation.Responses {
+			response := &apiResponse{Description: http.StatusText(code)}
+			if body != nil {
+				response.Content = jsonContent(schemas.schema(reflect.TypeOf(body)))
+			}
+
+			op.Responses[strconv.Itoa(code)] = response
+		}
+
+		if doc.Paths[path] == nil {
+			doc.Paths[path] = make(map[string]*apiOp)
+		}
+
+		doc.Paths[path][strings.ToLower(route.Method)] = op
+	}
+
+	if len(schemas.schemas) > 0 {
+		doc.Components = &apiComponents{Schemas: schemas.schemas}
+	}
+
+	return doc
+}
+
+// JSON returns the document encoded as JSON.
+func (doc *OpenAPIDocument) JSON() ([]byte, error) {
+	return json.MarshalIndent(doc, "", "  ")
+}
+
+// YAML returns the document encoded as YAML.
+func (doc *OpenAPIDocument) YAML() ([]byte, error) {
+	data, err := json.Marshal(doc)
+	if err != nil {
+		return nil, err
+	}
+
+	return jsonToYAML(data)
+}
+
+// OpenAPIHandler returns a http.Handler that serves the OpenAPI document
+// of the router, as YAML if the request path ends with .yaml or .yml,
+// or as JSON otherwise. The document is generated on every request,
+// so it also describes the routes registered after the handler.
+func OpenAPIHandler(r *Router, info OpenAPIInfo) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		doc := r.OpenAPI(info)
+
+		contentType, encode := "application/json", doc.JSON
+		if strings.HasSuffix(req.URL.Path, ".yaml") || strings.HasSuffix(req.URL.Path, ".yml") {
+			contentType, encode = "application/yaml", doc.YAML
+		}
+
+		data, err := encode()
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+
+		w.Header().Set("Content-Type", contentType)
+		w.Write(data)
+	})
+}
+
+// openAPIPath converts the route pattern into the OpenAPI path
+// template, e.g. /users/:id to /users/{id}, and returns its params.
+func openAPIPath(pattern string) (string, []apiParameter) {
+	segments := strings.Split(pattern, "/")
+
+	var parameters []apiParameter
+	for i, segment := range segments {
+		if segment == "" || (segment[0] != ':' && segment[0] != '*') {
+			continue
+		}
+
+		name := segment[1:]
+		segments[i] = "{" + name + "}"
+
+		parameter := apiParameter{
+			Name:     name,
+			In:       "path",
+			Required: true,
+			Schema:   &apiSchema{Type: "string"},
+		}
+
+		if segment[0] == '*' {
+			parameter.Description = "The rest of the path, including the '/' characters."
+		}
+
+		parameters = append(parameters, parameter)
+	}
+
+	return strings.Join(segments, "/"), parameters
+}
+
+func jsonContent(schema *apiSchema) map[string]apiMedia {
+	return map[string]apiMedia{"application/json": {Schema: schema}}
+}
+
+// schemaBuilder derives the JSON schemas of the Go types, and collects
+// the schemas of the named struct types for the document components.
+type schemaBuilder struct {
+	schemas map[string]*apiSchema
+	// names holds the component names of the described types.
+	names map[reflect.Type]string
+}
+
+var timeType = reflect.TypeOf(time.Time{})
+
+func (b *schemaBuilder) schema(t reflect.Type) *apiSchema {
+	for t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+
+	if t == timeType {
+		return &apiSchema{Type: "string", Format: "date-time"}
+	}
+
+	switch t.Kind() {
+	case reflect.Bool:
+		return &apiSchema{Type: "boolean"}
+	case reflect.Int8, reflect.Int16, reflect.Int32, reflect.Uint8, reflect.Uint16:
+		return &apiSchema{Type: "integer", Format: "int32"}
+	case reflect.Int, reflect.Int64, reflect.Uint, reflect.Uint32, reflect.Uint64:
+		return &apiSchema{Type: "integer", Format: "int64"}
+	case reflect.Float32:
+		return &apiSchema{Type: "number", Format: "float"}
+	case reflect.Float64:
+		return &apiSchema{Type: "number", Format: "double"}
+	case reflect.String:
+		return &apiSchema{Type: "string"}
+	case reflect.Slice, reflect.Array:
+		if t.Elem().Kind() == reflect.Uint8 {
+			return &apiSchema{Type: "string", Format: "byte"}
+		}
+
+		return &apiSchema{Type: "array", Items: b.schema(t.Elem())}
+	case reflect.Map:
+		return &apiSchema{Type: "object", AdditionalProperties: b.schema(t.Elem())}
+	case reflect.Struct:
+		if t.Name() == "" {
+			return b.structSchema(t)
+		}
+
+		name, ok := b.names[t]
+		if !ok {
+			name = componentName(t)
+			for _, taken := b.schemas[name]; taken; _, taken = b.schemas[name] {
+				name += "_"
+			}
+
+			// The placeholder stops the recursion of self-referencing types.
+			b.names[t] = name
+			b.schemas[name] = nil
+			b.schemas[name] = b.structSchema(t)
+		}
+
+		return &apiSchema{Ref: "#/components/schemas/" + name}
+	}
+
+	// Interfaces accept any value.
+	return &apiSchema{}
+}
+
+// structSchema describes the fields of the struct, named by their json tags.
+func (b *schemaBuilder) structSchema(t reflect.Type) *apiSchema {
+	schema := &apiSchema{Type: "object", Properties: make(map[string]*apiSchema)}
+
+	b.addFields(schema, t)
+	sort.Strings(schema.Required)
+
+	return schema
+}
+
+func (b *schemaBuilder) addFields(schema *apiSchema, t reflect.Type) {
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+
+		tag := field.Tag.Get("json")
+		if tag == "-" {
+			continue
+		}
+
+		name, options := tag, ""
+		if comma := strings.IndexByte(tag, ','); comma >= 0 {
+			name, options = tag[:comma], tag[comma:]
+		}
+
+		// The fields of embedded structs are promoted, as in encoding/json.
+		if field.Anonymous && name == "" {
+			embedded := field.Type
+			if embedded.Kind() == reflect.Ptr {
+				embedded = embedded.Elem()
+			}
+
+			if embedded.Kind() == reflect.Struct {
+				b.addFields(schema, embedded)
+				continue
+			}
+		}
+
+		if field.PkgPath != "" {
+			continue
+		}
+
+		if name == "" {
+			name = field.Name
+		}
+
+		schema.Properties[name] = b.schema(field.Type)
+
+		if !strings.Contains(options, ",omitempty") && field.Type.Kind() != reflect.Ptr {
+			schema.Required = append(schema.Required, name)
+		}
+	}
+}
+
+// componentName returns the name of the type in the schema components,
+// made of its package path and name, with the characters not allowed in
+// the component names, e.g. '/' or the brackets of generic types, replaced.
+func componentName(t reflect.Type) string {
+	name := t.Name()
+	if t.PkgPath() != "" {
+		name = t.PkgPath() + "." + name
+	}
+
+	return strings.Map(func(r rune) rune {
+		if r >= 'a' && r <= 'z' || r >= 'A' && r <= 'Z' || r >= '0' && r <= '9' || r == '.' || r == '-' || r == '_' {
+			return r
+		}
+
+		return '_'
+	}, name)
+}
+
+// operationIDs returns the unique operationIds of the routes. A name shared
+// by several routes gets the method appended, and the routes without a name,
+// or with an id that is still not unique, are left without one.
+func operationIDs(routes []*Route) map[*Route]string {
+	counts := make(map[string]int)
+	for _, route := range routes {
+		if route.Name != "" {
+			counts[route.Name]++
+		}
+	}
+
+	ids := make(map[*Route]string)
+	used := make(map[string]int)
+
+	for _, route := range routes {
+		if route.Name == "" {
+			continue
+		}
+
+		id := route.Name
+		if counts[route.Name] > 1 {
+			id += "." + strings.ToLower(route.Method)
+		}
+
+		ids[route] = id
+		used[id]++
+	}
+
+	for route, id := range ids {
+		if used[id] > 1 {
+			delete(ids, route)
+		}
+	}
+
+	return ids
+}
@@ -0,0 +1,181 @@
+package hyper
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+)
+
+// testComponents is the prefix of the component names of this package.
+const testComponents = "github.com_bencicandrej_hyper-router."
+
+type apiUser struct {
+	ID      int64      `json:"id"`
+	Name    string     `json:"name"`
+	Email   string     `json:"email,omitempty"`
+	Friends []*apiUser `json:"friends,omitempty"`
+	Created time.Time  `json:"created"`
+	secret  string
+}
+
+type createUser struct {
+	Name   string            `json:"name"`
+	Labels map[string]string `json:"labels,omitempty"`
+	Ignore bool              `json:"-"`
+}
+
+func TestOpenAPI(t *testing.T) {
+	router := NewRouter()
+	router.Get("/users/:id", emptyHandler).Named("getUser").
+		WithSummary("Get a user").
+		WithTags("users").
+		WithResponse(http.StatusOK, apiUser{}).
+		WithResponse(http.StatusNotFound, nil)
+	router.Post("/users", emptyHandler).
+		WithRequest(&createUser{}).
+		WithResponse(http.StatusCreated, apiUser{})
+	router.Get("/static/*filepath", emptyHandler)
+	router.Any("/any", emptyHandler)
+
+	doc := router.OpenAPI(OpenAPIInfo{Title: "Users", Version: "1.0"})
+
+	if len(doc.Paths) != 3 {
+		t.Fatalf("OpenAPI(): got paths %v", doc.Paths)
+	}
+
+	get := doc.Paths["/users/{id}"]["get"]
+	if get == nil || get.OperationID != "getUser" || get.Summary != "Get a user" || !reflect.DeepEqual(get.Tags, []string{"users"}) {
+		t.Fatalf("OpenAPI(): got operation %+v", get)
+	}
+
+	if len(get.Parameters) != 1 || get.Parameters[0].Name != "id" || get.Parameters[0].In != "path" {
+		t.Errorf("OpenAPI(): got parameters %+v", get.Parameters)
+	}
+
+	if ref := get.Responses["200"].Content["application/json"].Schema.Ref; ref != "#/components/schemas/"+testComponents+"apiUser" {
+		t.Errorf("OpenAPI(): got response schema %q", ref)
+	}
+
+	if get.Responses["404"].Content != nil || get.Responses["404"].Description != "Not Found" {
+		t.Errorf("OpenAPI(): got response %+v", get.Responses["404"])
+	}
+
+	if _, ok := doc.Paths["/static/{filepath}"]["get"]; !ok {
+		t.Errorf("OpenAPI(): missing wildcard path")
+	}
+
+	user := doc.Components.Schemas[testComponents+"apiUser"]
+	if !reflect.DeepEqual(user.Required, []string{"created", "id", "name"}) {
+		t.Errorf("OpenAPI(): got required %v", user.Required)
+	}
+
+	if user.Properties["friends"].Items.Ref != "#/components/schemas/"+testComponents+"apiUser" || user.Properties["created"].Format != "date-time" {
+		t.Errorf("OpenAPI(): got properties %+v", user.Properties)
+	}
+
+	if _, ok := user.Properties["secret"]; ok {
+		t.Errorf("OpenAPI(): got unexported field")
+	}
+
+	create := doc.Components.Schemas[testComponents+"createUser"]
+	if len(create.Properties) != 2 || create.Properties["labels"].AdditionalProperties.Type != "string" {
+		t.Errorf("OpenAPI(): got schema %+v", create)
+	}
+
+	if _, err := doc.JSON(); err != nil {
+		t.Errorf("OpenAPI(): got JSON error %s", err)
+	}
+}
+
+type Cookie struct {
+	Name string `json:"name"`
+}
+
+type page[T any] struct {
+	Items []T `json:"items"`
+}
+
+func TestOpenAPIUniqueNames(t *testing.T) {
+	router := NewRouter()
+
+	users := router.Route("/users/:id").Name("user")
+	users.Get(emptyHandler)
+	users.Put(emptyHandler)
+	router.Get("/cookies", emptyHandler).Named("cookies").
+		WithResponse(http.StatusOK, Cookie{}).
+		WithRequest(http.Cookie{})
+	router.Get("/pages", emptyHandler).WithResponse(http.StatusOK, page[apiUser]{})
+
+	doc := router.OpenAPI(OpenAPIInfo{Title: "Users", Version: "1.0"})
+
+	if get, put := doc.Paths["/users/{id}"]["get"], doc.Paths["/users/{id}"]["put"]; get.OperationID != "user.get" || put.OperationID != "user.put" {
+		t.Errorf("OpenAPI(): got operationIds '%s' and '%s'", get.OperationID, put.OperationID)
+	}
+
+	if id := doc.Paths["/cookies"]["get"].OperationID; id != "cookies" {
+		t.Errorf("OpenAPI(): got operationId '%s', wanted 'cookies'", id)
+	}
+
+	for _, name := range []string{
+		testComponents + "Cookie",
+		"net_http.Cookie",
+		testComponents + "page_github.com_bencicandrej_hyper-router.apiUser_",
+	} {
+		if _, ok := doc.Components.Schemas[name]; !ok {
+			t.Errorf("OpenAPI(): missing component %s", name)
+		}
+	}
+}
+
+func TestOpenAPIHandler(t *testing.T) {
+	router := NewRouter()
+	router.Get("/users/:id", emptyHandler)
+
+	handler := OpenAPIHandler(router, OpenAPIInfo{Title: "Users", Version: "1.0"})
+
+	w := httptest.NewRecorder()
+	handler.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/openapi.json", nil))
+
+	var doc map[string]interface{}
+	if err := json.Unmarshal(w.Body.Bytes(), &doc); err != nil || doc["openapi"] != "3.1.0" {
+		t.Errorf("OpenAPIHandler(): got %s, %v", w.Body, err)
+	}
+
+	w = httptest.NewRecorder()
+	handler.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/openapi.yaml", nil))
+
+	if w.Header().Get("Content-Type") != "application/yaml" || !strings.HasPrefix(w.Body.String(), "openapi: \"3.1.0\"\n") {
+		t.Errorf("OpenAPIHandler(): got YAML\n%s", w.Body)
+	}
+}
+
+func TestJSONToYAML(t *testing.T) {
+	input := `{"a": 1, "true": "x", "list": [{"b": null, "c": [true]}, "s\n"], "empty": {}, "none": [], "/p/{id}": {"$ref": "#/x"}}`
+
+	expected := `a: 1
+"true": "x"
+list:
+  -
+    b: null
+    c:
+      - true
+  - "s\n"
+empty: {}
+none: []
+"/p/{id}":
+  "$ref": "#/x"
+`
+
+	got, err := jsonToYAML([]byte(input))
+	if err != nil {
+		t.Fatalf("jsonToYAML(): got error %s", err)
+	}
+
+	if string(got) != expected {
+		t.Errorf("jsonToYAML(): got\n%s\nwanted\n%s", got, expected)
+	}
+}
@@ -41,3 +41,9 @@ func FromContext(ctx context.Context) (Params, bool) {
 
 	return p, ok
 }
+
+// WithParams returns a new context.Context carrying the provided params,
+// replacing the params of the parent context.
+func WithParams(ctx context.Context, ps Params) context.Context {
+	return context.WithValue(ctx, paramsKey, ps)
+}
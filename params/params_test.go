@@ -102,3 +102,17 @@ func TestContextSetAndRetrieve(t *testing.T) {
 		}
 	}
 }
+
+func TestWithParams(t *testing.T) {
+	ctx := NewContext(context.Background(), "foo", "bar")
+	ctx = WithParams(ctx, Params{Param{"baz", "foo"}})
+
+	params, _ := FromContext(ctx)
+	if _, ok := params.ByName("foo"); ok {
+		t.Error("params.ByName('foo'): got ok, wanted the param to be replaced")
+	}
+
+	if got, ok := params.ByName("baz"); got != "foo" || !ok {
+		t.Errorf("params.ByName('baz'): ('%s', '%v'), wanted ('foo', 'true')", got, ok)
+	}
+}
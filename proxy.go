@@ -0,0 +1,209 @@
+package hyper
+
+import (
+	"context"
+	"errors"
+	"net"
+	"net/http"
+	"net/http/httputil"
+	"net/url"
+	"path"
+	"strings"
+	"time"
+
+	"github.com/bencicandrej/hyper-router/params"
+)
+
+// ProxyOptions configures a reverse-proxy route.
+type ProxyOptions struct {
+	// Method is the method the route is registered for.
+	// Empty proxies every method, the same as MethodAny.
+	Method string
+	// Path is the pattern of the upstream path, with the parameters
+	// of the route substituted, e.g. /v2/users/:id for /api/users/:id.
+	// Empty forwards the request path unchanged. The path is joined
+	// with the path of the target URL. The values are cleaned, and the
+	// requests with values leading out of the upstream path, e.g. with
+	// ../../admin, are answered with 400.
+	Path string
+	// SetHeaders are added to the upstream request,
+	// replacing the incoming values.
+	SetHeaders http.Header
+	// StripHeaders are removed from the upstream request.
+	StripHeaders []string
+	// StripResponseHeaders are removed from the upstream response.
+	StripResponseHeaders []string
+	// TrustForwarded keeps the X-Forwarded-* and Forwarded headers
+	// of the incoming request, appending the client to them.
+	// Otherwise, the headers are replaced.
+	TrustForwarded bool
+	// Timeout limits the duration of the upstream request.
+	// Requests that time out are answered with 504.
+	Timeout time.Duration
+	// Transport is used to make the upstream requests,
+	// http.DefaultTransport if nil.
+	Transport http.RoundTripper
+}
+
+// Proxy forwards the requests matching the pattern to the target,
+// adding the X-Forwarded-For, X-Forwarded-Host, X-Forwarded-Proto
+// and Forwarded headers.
+func (r *Router) Proxy(pattern string, target *url.URL, options ProxyOptions) *Route {
+	if options.Path != "" {
+		if err := (Rule{From: pattern, To: options.Path}).validate(); err != nil {
+			panic(err.Error())
+		}
+	}
+
+	method := options.Method
+	if method == "" {
+		method = MethodAny
+	}
+
+	return r.Handle(method, pattern, newProxyHandler(target, options))
+}
+
+type proxyHandler struct {
+	proxy   *httputil.ReverseProxy
+	timeout time.Duration
+	// cleanParams is set when the params are substituted in the upstream path.
+	cleanParams bool
+}
+
+func newProxyHandler(target *url.URL, options ProxyOptions) *proxyHandler {
+	proxy := &httputil.ReverseProxy{
+		Transport: options.Transport,
+		Rewrite: func(pr *httputil.ProxyRequest) {
+			if options.Path != "" {
+				ps, _ := params.FromContext(pr.In.Context())
+
+				// The expanded path is escaped, the values can contain '?' or '#'.
+				expanded := expandPattern(options.Path, ps)
+				pr.Out.URL.Path, _ = url.PathUnescape(expanded)
+				pr.Out.URL.RawPath = expanded
+			}
+
+			pr.SetURL(target)
+
+			if options.TrustForwarded {
+				pr.Out.Header["X-Forwarded-For"] = pr.In.Header["X-Forwarded-For"]
+			}
+			pr.SetXForwarded()
+			setForwarded(pr, options.TrustForwarded)
+
+			for _, name := range options.StripHeaders {
+				pr.Out.Header.Del(name)
+			}
+
+			for name, values := range options.SetHeaders {
+				pr.Out.Header[http.CanonicalHeaderKey(name)] = append([]string{}, values...)
+			}
+		},
+		ErrorHandler: func(w http.ResponseWriter, req *http.Request, err error) {
+			if errors.Is(err, context.DeadlineExceeded) {
+				http.Error(w, http.StatusText(http.StatusGatewayTimeout), http.StatusGatewayTimeout)
+				return
+			}
+
+			http.Error(w, http.StatusText(http.StatusBadGateway), http.StatusBadGateway)
+		},
+	}
+
+	if len(options.StripResponseHeaders) > 0 {
+		proxy.ModifyResponse = func(res *http.Response) error {
+			for _, name := range options.StripResponseHeaders {
+				res.Header.Del(name)
+			}
+
+			return nil
+		}
+	}
+
+	return &proxyHandler{
+		proxy:   proxy,
+		timeout: options.Timeout,
+
+		cleanParams: options.Path != "",
+	}
+}
+
+func (h *proxyHandler) ServeHTTP(w http.ResponseWriter, req *http.Request) {
+	if h.cleanParams {
+		ps, _ := params.FromContext(req.Context())
+
+		cleaned, ok := cleanPathParams(ps)
+		if !ok {
+			http.Error(w, http.StatusText(http.StatusBadRequest), http.StatusBadRequest)
+			return
+		}
+
+		req = req.WithContext(params.WithParams(req.Context(), cleaned))
+	}
+
+	if h.timeout > 0 {
+		ctx, cancel := context.WithTimeout(req.Context(), h.timeout)
+		defer cancel()
+
+		req = req.WithContext(ctx)
+	}
+
+	h.proxy.ServeHTTP(w, req)
+}
+
+// cleanPathParams returns the params with the '.' and '..' segments of
+// their values resolved, and false if a value leads out of its position
+// in the path, e.g. ../../admin.
+func cleanPathParams(ps params.Params) (params.Params, bool) {
+	cleaned := make(params.Params, len(ps))
+
+	for i, p := range ps {
+		value := p.Value
+		if value != "" {
+			value = path.Clean(value)
+			if strings.HasSuffix(p.Value, "/") && value != "/" {
+				value += "/"
+			}
+		}
+
+		if value == ".." || strings.HasPrefix(value, "../") {
+			return nil, false
+		}
+
+		cleaned[i] = params.Param{Key: p.Key, Value: value}
+	}
+
+	return cleaned, true
+}
+
+// setForwarded sets the RFC 7239 Forwarded header of the upstream request.
+func setForwarded(pr *httputil.ProxyRequest, trust bool) {
+	proto := "http"
+	if pr.In.TLS != nil {
+		proto = "https"
+	}
+
+	element := "proto=" + proto + ";host=" + quoteForwarded(pr.In.Host)
+	if ip, _, err := net.SplitHostPort(pr.In.RemoteAddr); err == nil {
+		if strings.Contains(ip, ":") {
+			ip = "[" + ip + "]"
+		}
+
+		element = "for=" + quoteForwarded(ip) + ";" + element
+	}
+
+	if prior := pr.In.Header.Get("Forwarded"); trust && prior != "" {
+		element = prior + ", " + element
+	}
+
+	pr.Out.Header.Set("Forwarded", element)
+}
+
+// quoteForwarded quotes the Forwarded header value if it contains
+// characters that are not allowed in a token, e.g. ':' or '['.
+func quoteForwarded(value string) string {
+	if strings.ContainsAny(value, ":[]\"") {
+		return `"` + strings.ReplaceAll(value, `"`, `\"`) + `"`
+	}
+
+	return value
+}
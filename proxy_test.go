@@ -0,0 +1,92 @@
+package hyper
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestRouterProxy(t *testing.T) {
+	upstream := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/backend/slow" {
+			time.Sleep(100 * time.Millisecond)
+		}
+
+		w.Header().Set("X-Upstream", "secret")
+		w.Header().Set("X-Path", r.URL.RequestURI())
+		w.Header().Set("X-Token", r.Header.Get("X-Token"))
+		w.Header().Set("X-Cookie", r.Header.Get("Cookie"))
+		w.Header().Set("X-Forwarded", r.Header.Get("Forwarded"))
+	}))
+	defer upstream.Close()
+
+	target, _ := url.Parse(upstream.URL + "/backend")
+
+	router := NewRouter()
+	router.Proxy("/api/users/:id", target, ProxyOptions{
+		Path:                 "/v2/users/:id",
+		SetHeaders:           http.Header{"X-Token": {"internal"}},
+		StripHeaders:         []string{"Cookie"},
+		StripResponseHeaders: []string{"X-Upstream"},
+	})
+	router.Proxy("/api/files/*path", target, ProxyOptions{Path: "/v2/files/*path"})
+	router.Proxy("/slow", target, ProxyOptions{Timeout: 10 * time.Millisecond})
+
+	req := httptest.NewRequest(http.MethodPut, "/api/users/42?fields=name", nil)
+	req.Header.Set("Cookie", "session=1")
+	req.Header.Set("X-Token", "external")
+
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	tests := []struct {
+		header, want string
+	}{
+		{"X-Path", "/backend/v2/users/42?fields=name"},
+		{"X-Token", "internal"},
+		{"X-Cookie", ""},
+		{"X-Upstream", ""},
+	}
+
+	for _, test := range tests {
+		if got := w.Header().Get(test.header); got != test.want {
+			t.Errorf("PUT /api/users/42: got %s '%s', wanted '%s'", test.header, got, test.want)
+		}
+	}
+
+	if forwarded := w.Header().Get("X-Forwarded"); !strings.Contains(forwarded, "for=192.0.2.1;proto=http;host=example.com") {
+		t.Errorf("PUT /api/users/42: got Forwarded '%s'", forwarded)
+	}
+
+	paths := []struct {
+		path, upstream string
+		code           int
+	}{
+		{"/api/files/a/./b/../c", "/backend/v2/files/a/c", http.StatusOK},
+		{"/api/files/docs/", "/backend/v2/files/docs/", http.StatusOK},
+		{"/api/files/a%3Fx=1%23y", "/backend/v2/files/a%3Fx=1%23y", http.StatusOK},
+		{"/api/files/../../admin", "", http.StatusBadRequest},
+		{"/api/files/a/../../admin", "", http.StatusBadRequest},
+		{"/api/users/..", "", http.StatusBadRequest},
+	}
+
+	for _, test := range paths {
+		w = httptest.NewRecorder()
+		router.ServeHTTP(w, httptest.NewRequest(http.MethodGet, test.path, nil))
+
+		if w.Code != test.code || w.Header().Get("X-Path") != test.upstream {
+			t.Errorf("GET %s: got status %d and upstream path '%s', wanted %d and '%s'",
+				test.path, w.Code, w.Header().Get("X-Path"), test.code, test.upstream)
+		}
+	}
+
+	w = httptest.NewRecorder()
+	router.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/slow", nil))
+
+	if w.Code != http.StatusGatewayTimeout {
+		t.Errorf("GET /slow: got status %d, wanted %d", w.Code, http.StatusGatewayTimeout)
+	}
+}
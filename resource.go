@@ -0,0 +1,222 @@
+package hyper
+
+import (
+	"fmt"
+	"net/http"
+	"strings"
+
+	"github.com/bencicandrej/hyper-router/internal/inflect"
+)
+
+// Indexer is implemented by resource controllers that list the resource collection.
+type Indexer interface {
+	Index(w http.ResponseWriter, r *http.Request)
+}
+
+// Creator is implemented by resource controllers that create a new resource.
+type Creator interface {
+	Create(w http.ResponseWriter, r *http.Request)
+}
+
+// Shower is implemented by resource controllers that show a single resource.
+type Shower interface {
+	Show(w http.ResponseWriter, r *http.Request)
+}
+
+// Updater is implemented by resource controllers that replace a single resource.
+type Updater interface {
+	Update(w http.ResponseWriter, r *http.Request)
+}
+
+// Patcher is implemented by resource controllers that partially update a single resource.
+type Patcher interface {
+	Patch(w http.ResponseWriter, r *http.Request)
+}
+
+// Deleter is implemented by resource controllers that delete a single resource.
+type Deleter interface {
+	Delete(w http.ResponseWriter, r *http.Request)
+}
+
+// Names of the resource actions, as used by the Only and Except options
+// and as the suffix of the resource route names.
+const (
+	ActionIndex  = "index"
+	ActionCreate = "create"
+	ActionShow   = "show"
+	ActionUpdate = "update"
+	ActionPatch  = "patch"
+	ActionDelete = "delete"
+)
+
+var resourceActions = []string{ActionIndex, ActionCreate, ActionShow, ActionUpdate, ActionPatch, ActionDelete}
+
+// ResourceOption configures the routes registered by Router.Resource.
+type ResourceOption func(*resourceConfig)
+
+type resourceConfig struct {
+	actions map[string]bool
+	param   string
+	name    string
+}
+
+// Only limits the registered routes to the listed actions.
+func Only(actions ...string) ResourceOption {
+	return func(config *resourceConfig) {
+		for _, action := range resourceActions {
+			config.actions[action] = false
+		}
+
+		for _, action := range actions {
+			config.setAction(action, true)
+		}
+	}
+}
+
+// Except skips the registration of the listed actions.
+func Except(actions ...string) ResourceOption {
+	return func(config *resourceConfig) {
+		for _, action := range actions {
+			config.setAction(action, false)
+		}
+	}
+}
+
+// IDParam sets the name of the parameter identifying a single resource.
+func IDParam(name string) ResourceOption {
+	return func(config *resourceConfig) {
+		config.param = name
+	}
+}
+
+// ResourceName sets the prefix of the resource route names.
+func ResourceName(name string) ResourceOption {
+	return func(config *resourceConfig) {
+		config.name = name
+	}
+}
+
+func (config *resourceConfig) setAction(action string, enabled bool) {
+	if _, ok := config.actions[action]; !ok {
+		panic(fmt.Sprintf("unknown resource action '%s'", action))
+	}
+
+	config.actions[action] = enabled
+}
+
+// Resource is a set of conventional routes registered for a controller.
+type Resource struct {
+	name  string
+	param string
+
+	// Collection holds the routes of the resource collection, e.g. /photos.
+	Collection *RouteBuilder
+	// Member holds the routes of a single resource, e.g. /photos/:id.
+	Member *RouteBuilder
+}
+
+// Resource registers the conventional routes for every action
+// the controller implements:
+//
+//	GET    /photos      Index   photos.index
+//	POST   /photos      Create  photos.create
+//	GET    /photos/:id  Show    photos.show
+//	PUT    /photos/:id  Update  photos.update
+//	PATCH  /photos/:id  Patch   photos.patch
+//	DELETE /photos/:id  Delete  photos.delete
+//
+// Resource panics if the controller does not implement any
+// of the enabled actions.
+func (r *Router) Resource(path string, controller interface{}, options ...ResourceOption) *Resource {
+	return r.resource(path, "", controller, "id", options)
+}
+
+// Resource registers a resource nested under a single resource, e.g.
+// /photos/:id/comments. The identifying parameter of the nested resource
+// defaults to the singular of the last segment of its path, followed
+// by '_id' (e.g. comment_id or category_id), so it does not shadow the
+// parent one. Use the IDParam option for the irregular plurals.
+func (res *Resource) Resource(path string, controller interface{}, options ...ResourceOption) *Resource {
+	param := inflect.Singular(lastSegment(path)) + "_id"
+
+	return res.Member.router.resource(res.Member.path+path, res.name, controller, param, options)
+}
+
+// Use adds middleware to all routes of the resource.
+func (res *Resource) Use(middleware ...Middleware) *Resource {
+	res.Collection.Use(middleware...)
+	res.Member.Use(middleware...)
+
+	return res
+}
+
+func (r *Router) resource(path, prefix string, controller interface{}, param string, options []ResourceOption) *Resource {
+	config := &resourceConfig{
+		actions: make(map[string]bool),
+		param:   param,
+		name:    lastSegment(path),
+	}
+
+	for _, action := range resourceActions {
+		config.actions[action] = true
+	}
+
+	for _, option := range options {
+		option(config)
+	}
+
+	if prefix != "" {
+		config.name = prefix + "." + config.name
+	}
+
+	res := &Resource{
+		name:  config.name,
+		param: config.param,
+
+		Collection: r.Route(path),
+		Member:     r.Route(strings.TrimSuffix(path, "/") + "/:" + config.param),
+	}
+
+	registered := 0
+	register := func(b *RouteBuilder, action, method string, handler func(http.ResponseWriter, *http.Request)) {
+		if !config.actions[action] {
+			return
+		}
+
+		b.Handle(method, http.HandlerFunc(handler))
+		b.routes[len(b.routes)-1].Named(config.name + "." + action)
+		registered++
+	}
+
+	if c, ok := controller.(Indexer); ok {
+		register(res.Collection, ActionIndex, http.MethodGet, c.Index)
+	}
+	if c, ok := controller.(Creator); ok {
+		register(res.Collection, ActionCreate, http.MethodPost, c.Create)
+	}
+	if c, ok := controller.(Shower); ok {
+		register(res.Member, ActionShow, http.MethodGet, c.Show)
+	}
+	if c, ok := controller.(Updater); ok {
+		register(res.Member, ActionUpdate, http.MethodPut, c.Update)
+	}
+	if c, ok := controller.(Patcher); ok {
+		register(res.Member, ActionPatch, http.MethodPatch, c.Patch)
+	}
+	if c, ok := controller.(Deleter); ok {
+		register(res.Member, ActionDelete, http.MethodDelete, c.Delete)
+	}
+
+	if registered == 0 {
+		panic(fmt.Sprintf("controller for resource '%s' does not implement any enabled action", path))
+	}
+
+	return res
+}
+
+// lastSegment returns the last non-empty segment of the path.
+func lastSegment(path string) string {
+	path = strings.TrimSuffix(path, "/")
+
+	return path[strings.LastIndexByte(path, '/')+1:]
+}
@@ -0,0 +1,68 @@
+package hyper
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/bencicandrej/hyper-router/params"
+)
+
+type photosController struct{}
+
+func (photosController) Index(w http.ResponseWriter, r *http.Request)  { w.Write([]byte("index")) }
+func (photosController) Create(w http.ResponseWriter, r *http.Request) { w.Write([]byte("create")) }
+func (photosController) Show(w http.ResponseWriter, r *http.Request)   { w.Write([]byte("show")) }
+func (photosController) Delete(w http.ResponseWriter, r *http.Request) { w.Write([]byte("delete")) }
+
+type commentsController struct{}
+
+func (commentsController) Show(w http.ResponseWriter, r *http.Request) {
+	ps, _ := params.FromContext(r.Context())
+	photo, _ := ps.ByName("id")
+	comment, _ := ps.ByName("comment_id")
+	w.Write([]byte(photo + "/" + comment))
+}
+
+func TestRouterResource(t *testing.T) {
+	router := NewRouter()
+	router.Resource("/photos", photosController{}, Except(ActionDelete)).
+		Resource("/comments", commentsController{})
+
+	tests := []struct {
+		method, path string
+		code         int
+		body         string
+	}{
+		{http.MethodGet, "/photos", http.StatusOK, "index"},
+		{http.MethodPost, "/photos", http.StatusOK, "create"},
+		{http.MethodGet, "/photos/1", http.StatusOK, "show"},
+		{http.MethodDelete, "/photos/1", http.StatusMethodNotAllowed, ""},
+		{http.MethodPut, "/photos/1", http.StatusMethodNotAllowed, ""},
+		{http.MethodGet, "/photos/1/comments/2", http.StatusOK, "1/2"},
+		{http.MethodGet, "/photos/1/comments", http.StatusNotFound, ""},
+	}
+
+	for _, test := range tests {
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, httptest.NewRequest(test.method, test.path, nil))
+
+		if w.Code != test.code {
+			t.Errorf("%s %s: got status %d, wanted %d", test.method, test.path, w.Code, test.code)
+		}
+
+		if test.code == http.StatusOK && w.Body.String() != test.body {
+			t.Errorf("%s %s: got body '%s', wanted '%s'", test.method, test.path, w.Body.String(), test.body)
+		}
+	}
+}
+
+func TestRouterResourceWithoutActions(t *testing.T) {
+	defer func() {
+		if recover() == nil {
+			t.Error("router.Resource(): expected panic, got none")
+		}
+	}()
+
+	NewRouter().Resource("/photos", photosController{}, Only(ActionUpdate))
+}
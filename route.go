@@ -0,0 +1,138 @@
+package hyper
+
+import (
+	"context"
+	"net/http"
+	"sync/atomic"
+)
+
+type ctxKey int
+
+var routeKey ctxKey = 0
+
+// Matcher is an additional condition a request must satisfy
+// for the route to be served, besides its method and path.
+type Matcher func(req *http.Request) bool
+
+// Route is a single route registered on the Router.
+//
+// The matched Route is stored in the request context
+// and can be retrieved with the RouteFromContext function.
+type Route struct {
+	// Method is the method the route was registered for,
+	// or MethodAny for routes that serve every method.
+	Method string
+	// Pattern is the original path of the route,
+	// e.g. /api/v1/users/:id/sites/*url.
+	Pattern string
+	// Name is an optional, user defined name of the route.
+	Name string
+	// Meta holds arbitrary, user defined route metadata.
+	Meta map[string]interface{}
+	// Operation describes the route in the OpenAPI document.
+	Operation Operation
+
+	matchers   []Matcher
+	middleware []Middleware
+
+	// base is the handler as it was registered, and handler
+	// is the base handler wrapped in the route middleware.
+	base    http.Handler
+	handler http.Handler
+
+	hits atomic.Uint64
+}
+
+func newRoute(method, pattern string, handler http.Handler, middleware []Middleware) *Route {
+	route := &Route{
+		Method:  method,
+		Pattern: pattern,
+
+		middleware: append([]Middleware{}, middleware...),
+
+		base: handler,
+	}
+
+	route.build()
+
+	return route
+}
+
+// Named sets the name of the route and returns the route
+// so it can be used inline with the route registration.
+func (route *Route) Named(name string) *Route {
+	route.Name = name
+
+	return route
+}
+
+// Use adds middleware to the route, as the last ones in the request flow.
+func (route *Route) Use(middleware ...Middleware) *Route {
+	route.middleware = append(route.middleware, middleware...)
+	route.build()
+
+	return route
+}
+
+// WithMeta stores a metadata value under the key.
+func (route *Route) WithMeta(key string, value interface{}) *Route {
+	if route.Meta == nil {
+		route.Meta = make(map[string]interface{})
+	}
+
+	route.Meta[key] = value
+
+	return route
+}
+
+// Match adds matchers the request must satisfy to be served by
+// the route. Requests that fail any of them are treated as not found.
+func (route *Route) Match(matchers ...Matcher) *Route {
+	route.matchers = append(route.matchers, matchers...)
+
+	return route
+}
+
+// Hits returns the number of requests the route has served.
+func (route *Route) Hits() uint64 {
+	return route.hits.Load()
+}
+
+// matches checks the request against all matchers of the route.
+func (route *Route) matches(req *http.Request) bool {
+	for _, matcher := range route.matchers {
+		if !matcher(req) {
+			return false
+		}
+	}
+
+	return true
+}
+
+// build constructs the final handler of the route, so the
+// middleware are not wrapped again on every request.
+func (route *Route) build() {
+	route.handler = route.base
+
+	if len(route.middleware) > 0 {
+		route.handler = NewStack(route.middleware...).Do(route.base)
+	}
+}
+
+// ServeHTTP stores the route in the request context
+// and calls the handler of the route.
+func (route *Route) ServeHTTP(w http.ResponseWriter, req *http.Request) {
+	route.handler.ServeHTTP(w, req.WithContext(NewRouteContext(req.Context(), route)))
+}
+
+// NewRouteContext returns a new context.Context carrying the route.
+func NewRouteContext(ctx context.Context, route *Route) context.Context {
+	return context.WithValue(ctx, routeKey, route)
+}
+
+// RouteFromContext extracts the matched route from a given context.
+func RouteFromContext(ctx context.Context) (*Route, bool) {
+	route, ok := ctx.Value(routeKey).(*Route)
+
+	return route, ok
+}
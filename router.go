@@ -1,14 +1,54 @@
 package hyper
 
 import (
+	"context"
 	"fmt"
 	"net/http"
+	"sort"
+	"strings"
 )
 
+// MethodAny is the method wildcard. Routes registered for it serve
+// every request method that has no route of its own on the same path.
+const MethodAny = "*"
+
 // Router is a http.Handler that is responsible for
 // registering and dispatching other handlers to correct routes.
 type Router struct {
-	handlerTrees map[string]*node
+	// Versioning configures how the API version of a request is selected
+	// when the routes are registered for versions with router.Version.
+	Versioning Versioning
+
+	// tree holds the routes of all methods, in the method-indexed
+	// handler tables of its nodes.
+	tree *node
+
+	// customMethods maps the custom methods to their handler table
+	// index, and methodNames holds them in the order of the index.
+	customMethods map[string]int
+	methodNames   []string
+
+	versions map[int]*Router
+	// versionOrder holds the registered versions, in the descending order.
+	versionOrder []int
+
+	// assets holds the file servers with fingerprinted assets.
+	assets []*fileServer
+
+	// frozen holds the compiled routes, once the router is frozen.
+	frozen *frozenTree
+
+	cache *lookupCache
+
+	// CountHits enables the counters of the requests served by each
+	// route, see route.Hits. The counters are shared by all cores,
+	// so they are disabled by default, to keep the lookup fast.
+	CountHits bool
+
+	// MaxBodySize limits the size of the request bodies validated against
+	// a JSON schema by the routes of router.LoadOpenAPI, DefaultMaxBodySize
+	// if zero. Larger bodies are rejected with 413.
+	MaxBodySize int64
 }
 
 // NewRouter return the an empty Router.
@@ -16,71 +56,213 @@ func NewRouter() *Router {
 	return &Router{}
 }
 
-// Get is a shortcut to the router.Handle(http.MethodGet, path, handler) method.
-func (r *Router) Get(path string, handler http.Handler) {
-	r.Handle(http.MethodGet, path, handler)
+// Get is a shortcut to the router.Handle(http.MethodGet, path, handler, middleware...) method.
+func (r *Router) Get(path string, handler http.Handler, middleware ...Middleware) *Route {
+	return r.Handle(http.MethodGet, path, handler, middleware...)
+}
+
+// Head is a shortcut to the router.Handle(http.MethodHead, path, handler, middleware...) method.
+func (r *Router) Head(path string, handler http.Handler, middleware ...Middleware) *Route {
+	return r.Handle(http.MethodHead, path, handler, middleware...)
 }
 
-// Get is a shortcut to the router.Handle(http.MethodHead, path, handler) method.
-func (r *Router) Head(path string, handler http.Handler) {
-	r.Handle(http.MethodHead, path, handler)
+// Options is a shortcut to the router.Handle(http.MethodOptions, path, handler, middleware...) method.
+func (r *Router) Options(path string, handler http.Handler, middleware ...Middleware) *Route {
+	return r.Handle(http.MethodOptions, path, handler, middleware...)
 }
 
-// Get is a shortcut to the router.Handle(http.MethodOptions, path, handler) method.
-func (r *Router) Options(path string, handler http.Handler) {
-	r.Handle(http.MethodOptions, path, handler)
+// Post is a shortcut to the router.Handle(http.MethodPost, path, handler, middleware...) method.
+func (r *Router) Post(path string, handler http.Handler, middleware ...Middleware) *Route {
+	return r.Handle(http.MethodPost, path, handler, middleware...)
 }
 
-// Get is a shortcut to the router.Handle(http.MethodPost, path, handler) method.
-func (r *Router) Post(path string, handler http.Handler) {
-	r.Handle(http.MethodPost, path, handler)
+// Put is a shortcut to the router.Handle(http.MethodPut, path, handler, middleware...) method.
+func (r *Router) Put(path string, handler http.Handler, middleware ...Middleware) *Route {
+	return r.Handle(http.MethodPut, path, handler, middleware...)
 }
 
-// Get is a shortcut to the router.Handle(http.MethodPut, path, handler) method.
-func (r *Router) Put(path string, handler http.Handler) {
-	r.Handle(http.MethodPut, path, handler)
+// Patch is a shortcut to the router.Handle(http.MethodPatch, path, handler, middleware...) method.
+func (r *Router) Patch(path string, handler http.Handler, middleware ...Middleware) *Route {
+	return r.Handle(http.MethodPatch, path, handler, middleware...)
 }
 
-// Get is a shortcut to the router.Handle(http.MethodPatch, path, handler) method.
-func (r *Router) Patch(path string, handler http.Handler) {
-	r.Handle(http.MethodPatch, path, handler)
+// Delete is a shortcut to the router.Handle(http.MethodDelete, path, handler, middleware...) method.
+func (r *Router) Delete(path string, handler http.Handler, middleware ...Middleware) *Route {
+	return r.Handle(http.MethodDelete, path, handler, middleware...)
 }
 
-// Get is a shortcut to the router.Handle(http.MethodDelete, path, handler) method.
-func (r *Router) Delete(path string, handler http.Handler) {
-	r.Handle(http.MethodDelete, path, handler)
+// Any is a shortcut to the router.Handle(MethodAny, path, handler, middleware...) method.
+// Handlers registered for a specific method on the same path take
+// precedence over the one registered with Any.
+func (r *Router) Any(path string, handler http.Handler, middleware ...Middleware) *Route {
+	return r.Handle(MethodAny, path, handler, middleware...)
 }
 
 // Handle adds a new route to the Router for the specified method and path.
-func (r *Router) Handle(method string, path string, handler http.Handler) {
-	if path[0] != '/' {
+//
+// Method can be any HTTP method token, including the custom ones
+// (e.g. PURGE or PROPFIND), or MethodAny to match every method.
+//
+// The optional middleware is applied only to this route. The final
+// handler is built once, at registration, in the same order as
+// the MiddlewareStack would build it.
+//
+// Handle returns the registered Route, which can be used
+// to further describe the route, e.g. to give it a name.
+func (r *Router) Handle(method string, path string, handler http.Handler, middleware ...Middleware) *Route {
+	if method == "" {
+		panic(fmt.Sprintf("method must not be empty for route '%s'", path))
+	}
+
+	if path == "" || path[0] != '/' {
 		panic(fmt.Sprintf("path must start with '/' in '%s'", path))
 	}
 
+	if handler == nil {
+		panic(fmt.Sprintf("handler must not be nil for route '%s'", path))
+	}
+
+	if r.frozen != nil {
+		panic(fmt.Sprintf("can not register route '%s' on a frozen router", path))
+	}
+
 	// If no routes are defined yet, create a new tree.
-	if r.handlerTrees == nil {
-		r.handlerTrees = make(map[string]*node)
+	if r.tree == nil {
+		r.tree = new(node)
 	}
 
-	// If tree does not exist for `method`, create a new one.
-	root, ok := r.handlerTrees[method]
-	if !ok {
-		root = new(node)
-		r.handlerTrees[method] = root
+	route := newRoute(method, path, handler, middleware)
+
+	r.tree.insert(nodeLabel(path), r.addMethod(method), route)
+
+	if r.cache != nil {
+		r.cache.purge()
 	}
 
-	root.insert(nodeLabel(path), handler)
+	return route
+}
+
+// TryHandle registers the route like router.Handle, but returns the error,
+// e.g. a conflict with a registered route, instead of panicking. The
+// routes of the router are left as they were when it fails.
+func (r *Router) TryHandle(method string, path string, handler http.Handler, middleware ...Middleware) (route *Route, err error) {
+	defer func() {
+		if recovered := recover(); recovered != nil {
+			route, err = nil, fmt.Errorf("%v", recovered)
+		}
+	}()
+
+	return r.Handle(method, path, handler, middleware...), nil
 }
 
 func (r *Router) ServeHTTP(w http.ResponseWriter, req *http.Request) {
 	path := req.URL.Path
 
-	if root, ok := r.handlerTrees[req.Method]; ok {
-		if handler, ctx := root.getHandler(req.Context(), nodeLabel(path)); handler != nil {
-			handler.ServeHTTP(w, req.WithContext(ctx))
+	// The routes of the Router itself serve every version, so they are
+	// looked up by the path without the version prefix as well.
+	var version int
+	if len(r.versions) > 0 {
+		version, path = r.requestVersion(req)
+
+		if r.serveVersion(w, req, version, path) {
 			return
 		}
 	}
 
+	if route, ctx := r.getRoute(req.Context(), req.Method, path); route != nil {
+		serveRoute(w, req, ctx, route, r.CountHits)
+		return
+	}
+
+	allowed := r.allowed(path)
+	if len(r.versions) > 0 {
+		allowed = mergeMethods(allowed, r.versionAllowed(version, path))
+	}
+
+	if len(allowed) > 0 {
+		w.Header().Set("Allow", strings.Join(allowed, ", "))
+		http.Error(w, http.StatusText(http.StatusMethodNotAllowed), http.StatusMethodNotAllowed)
+		return
+	}
+
 	http.NotFound(w, req)
 }
+
+// serveRoute calls the route handler with the lookup context, extended
+// with the route, or responds with 404 if the route matchers fail.
+func serveRoute(w http.ResponseWriter, req *http.Request, ctx context.Context, route *Route, countHits bool) {
+	if !route.matches(req) {
+		http.NotFound(w, req)
+		return
+	}
+
+	if countHits {
+		route.hits.Add(1)
+	}
+	route.handler.ServeHTTP(w, req.WithContext(NewRouteContext(ctx, route)))
+}
+
+// getRoute looks up the route for the method and path, falling back
+// to the MethodAny route when the method has no route of its own.
+func (r *Router) getRoute(ctx context.Context, method, path string) (*Route, context.Context) {
+	if r.cache != nil {
+		return r.getCachedRoute(ctx, method, path)
+	}
+
+	return r.lookupRoute(ctx, method, path)
+}
+
+// lookupRoute looks up the route in the route tree, bypassing the cache.
+func (r *Router) lookupRoute(ctx context.Context, method, path string) (*Route, context.Context) {
+	if index, ok := r.methodIndex(method); ok && index > 0 {
+		if handler, routeCtx := r.getHandler(ctx, path, index); handler != nil {
+			return handler.(*Route), routeCtx
+		}
+	}
+
+	if handler, routeCtx := r.getHandler(ctx, path, 0); handler != nil {
+		return handler.(*Route), routeCtx
+	}
+
+	return nil, ctx
+}
+
+// getHandler returns the handler of the method index for the path,
+// and the context carrying the params matched in the path.
+func (r *Router) getHandler(ctx context.Context, path string, method int) (http.Handler, context.Context) {
+	if r.frozen != nil {
+		handler, ps := r.frozen.lookup(path, method)
+
+		return handler, withParams(ctx, ps)
+	}
+
+	if r.tree == nil {
+		return nil, ctx
+	}
+
+	return r.tree.getHandler(ctx, nodeLabel(path), method)
+}
+
+// allowed returns a sorted list of methods that have a route for
+// the path. Routes registered with MethodAny are not listed, since
+// a path they match can never produce a 405 response.
+func (r *Router) allowed(path string) []string {
+	if r.tree == nil {
+		return nil
+	}
+
+	var methods []string
+	for index := 1; index < len(standardMethods)+len(r.methodNames); index++ {
+		if !r.tree.methods.has(index) {
+			continue
+		}
+
+		if handler, _ := r.getHandler(context.Background(), path, index); handler != nil {
+			methods = append(methods, r.methodName(index))
+		}
+	}
+
+	sort.Strings(methods)
+
+	return methods
+}
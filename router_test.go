@@ -0,0 +1,212 @@
+package hyper
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/bencicandrej/hyper-router/params"
+)
+
+func methodHandler(name string) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(name))
+	})
+}
+
+func TestRouterAnyMethod(t *testing.T) {
+	router := NewRouter()
+	router.Any("/proxy/*path", methodHandler("any"))
+	router.Get("/proxy/*path", methodHandler("get"))
+	router.Handle("PURGE", "/cache/:key", methodHandler("purge"))
+
+	tests := []struct {
+		method, path string
+		code         int
+		body         string
+	}{
+		{http.MethodGet, "/proxy/foo", http.StatusOK, "get"},
+		{http.MethodPost, "/proxy/foo", http.StatusOK, "any"},
+		{"PROPFIND", "/proxy/foo", http.StatusOK, "any"},
+		{"PURGE", "/cache/foo", http.StatusOK, "purge"},
+		{http.MethodGet, "/missing", http.StatusNotFound, ""},
+	}
+
+	for _, test := range tests {
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, httptest.NewRequest(test.method, test.path, nil))
+
+		if w.Code != test.code {
+			t.Errorf("%s %s: got status %d, wanted %d", test.method, test.path, w.Code, test.code)
+		}
+
+		if test.code == http.StatusOK && w.Body.String() != test.body {
+			t.Errorf("%s %s: got body '%s', wanted '%s'", test.method, test.path, w.Body.String(), test.body)
+		}
+	}
+}
+
+func TestRouterMethodNotAllowed(t *testing.T) {
+	router := NewRouter()
+	router.Get("/users/:id", emptyHandler)
+	router.Delete("/users/:id", emptyHandler)
+	router.Handle("PURGE", "/users/:id", emptyHandler)
+
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, httptest.NewRequest(http.MethodPost, "/users/1", nil))
+
+	if w.Code != http.StatusMethodNotAllowed {
+		t.Fatalf("POST /users/1: got status %d, wanted %d", w.Code, http.StatusMethodNotAllowed)
+	}
+
+	if allow, want := w.Header().Get("Allow"), "DELETE, GET, PURGE"; allow != want {
+		t.Errorf("POST /users/1: got Allow '%s', wanted '%s'", allow, want)
+	}
+}
+
+func TestRouterMethodRoutes(t *testing.T) {
+	paramsHandler := func(name string) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			ps, _ := params.FromContext(r.Context())
+			fmt.Fprintf(w, "%s %v", name, ps)
+		})
+	}
+
+	router := NewRouter()
+	router.Get("/users/:id", paramsHandler("get"))
+	router.Post("/users/search", paramsHandler("search"))
+	router.Put("/users/:uid", paramsHandler("put"))
+	router.Get("/files/*path", paramsHandler("files"))
+	router.Post("/files/new", paramsHandler("new"))
+
+	tests := []struct {
+		method, path string
+		code         int
+		body, allow  string
+	}{
+		{http.MethodGet, "/users/search", http.StatusOK, "get [{id search}]", ""},
+		{http.MethodPost, "/users/search", http.StatusOK, "search []", ""},
+		{http.MethodPut, "/users/42", http.StatusOK, "put [{uid 42}]", ""},
+		{http.MethodPost, "/users/42", http.StatusMethodNotAllowed, "", "GET, PUT"},
+		{http.MethodDelete, "/users/search", http.StatusMethodNotAllowed, "", "GET, POST, PUT"},
+		{http.MethodGet, "/files/new", http.StatusOK, "files [{path new}]", ""},
+		{http.MethodPost, "/files/new", http.StatusOK, "new []", ""},
+		{http.MethodPost, "/files/a", http.StatusMethodNotAllowed, "", "GET"},
+	}
+
+	check := func(frozen bool) {
+		for _, test := range tests {
+			w := httptest.NewRecorder()
+			router.ServeHTTP(w, httptest.NewRequest(test.method, test.path, nil))
+
+			if w.Code != test.code {
+				t.Errorf("%s %s (frozen %v): got status %d, wanted %d", test.method, test.path, frozen, w.Code, test.code)
+			}
+
+			if test.code == http.StatusOK && w.Body.String() != test.body {
+				t.Errorf("%s %s (frozen %v): got body '%s', wanted '%s'", test.method, test.path, frozen, w.Body.String(), test.body)
+			}
+
+			if allow := w.Header().Get("Allow"); allow != test.allow {
+				t.Errorf("%s %s (frozen %v): got Allow '%s', wanted '%s'", test.method, test.path, frozen, allow, test.allow)
+			}
+		}
+	}
+
+	check(false)
+	router.Freeze()
+	check(true)
+}
+
+func TestRouterTryHandle(t *testing.T) {
+	router := NewRouter()
+
+	if route, err := router.TryHandle(http.MethodGet, "/users/:id", emptyHandler); err != nil || route == nil {
+		t.Fatalf("router.TryHandle(): got route %v and error %v", route, err)
+	}
+
+	tests := []struct {
+		method, path string
+		handler      http.Handler
+		err          string
+	}{
+		{http.MethodGet, "/users/:id", emptyHandler, "handler for route '/users/:id' already exists"},
+		{http.MethodGet, "users", emptyHandler, "path must start with '/' in 'users'"},
+		{http.MethodGet, "/posts", nil, "handler must not be nil for route '/posts'"},
+	}
+
+	for _, test := range tests {
+		route, err := router.TryHandle(test.method, test.path, test.handler)
+		if route != nil || err == nil || err.Error() != test.err {
+			t.Errorf("router.TryHandle(%s %s): got route %v and error %v, wanted %q", test.method, test.path, route, err, test.err)
+		}
+	}
+}
+
+func TestRouterTryHandleConflictFree(t *testing.T) {
+	router := NewRouter()
+	router.Get("/x", emptyHandler)
+
+	if _, err := router.TryHandle(http.MethodGet, "/x/y/*w/z", emptyHandler); err == nil {
+		t.Fatal("router.TryHandle('/x/y/*w/z'): expected error, got none")
+	}
+
+	// The failed registration must not leave the /x/y/ node behind.
+	if _, err := router.TryHandle(http.MethodGet, "/x/:id", emptyHandler); err != nil {
+		t.Errorf("router.TryHandle('/x/:id'): got error %s", err)
+	}
+}
+
+func TestRouterRouteMiddleware(t *testing.T) {
+	header := func(value string) Middleware {
+		return func(next http.Handler) http.Handler {
+			return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				w.Header().Add("X-Middleware", value)
+				next.ServeHTTP(w, r)
+			})
+		}
+	}
+
+	router := NewRouter()
+	router.Get("/private", methodHandler("private"), header("auth"), header("cache"))
+	router.Get("/public", methodHandler("public"))
+
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/private", nil))
+
+	got := w.Header()["X-Middleware"]
+	if len(got) != 2 || got[0] != "auth" || got[1] != "cache" {
+		t.Errorf("GET /private: got middleware %v, wanted [auth cache]", got)
+	}
+
+	w = httptest.NewRecorder()
+	router.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/public", nil))
+
+	if got := w.Header()["X-Middleware"]; len(got) != 0 {
+		t.Errorf("GET /public: got middleware %v, wanted none", got)
+	}
+}
+
+func TestRouterRouteFromContext(t *testing.T) {
+	var got *Route
+
+	router := NewRouter()
+	router.Get("/api/v1/users/:id/sites/*url", http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		got, _ = RouteFromContext(r.Context())
+	})).Named("user.sites")
+
+	router.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/api/v1/users/1/sites/example.com", nil))
+
+	if got == nil {
+		t.Fatal("RouteFromContext(ctx): got no route")
+	}
+
+	if got.Method != http.MethodGet || got.Pattern != "/api/v1/users/:id/sites/*url" || got.Name != "user.sites" {
+		t.Errorf(
+			"RouteFromContext(ctx): got (%s, %s, %s), wanted (%s, %s, %s)",
+			got.Method, got.Pattern, got.Name,
+			http.MethodGet, "/api/v1/users/:id/sites/*url", "user.sites",
+		)
+	}
+}
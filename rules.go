@@ -0,0 +1,242 @@
+package hyper
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+
+	"github.com/bencicandrej/hyper-router/params"
+)
+
+var rewriteKey ctxKey = 2
+
+// maxRewrites limits the number of internal rewrites of a single
+// request, so rewrite rules pointing at each other can not loop forever.
+const maxRewrites = 10
+
+// Rule is a declarative redirect or internal rewrite.
+//
+// From and To use the same pattern syntax as the routes, and the
+// parameters matched by From are substituted in To, e.g. a rule
+// from /old/:id to /new/:id redirects /old/42 to /new/42.
+type Rule struct {
+	// Method is the method the rule applies to. Empty applies
+	// the rule to every method, the same as MethodAny.
+	Method string `json:"method,omitempty"`
+	// From is the pattern of the matched path.
+	From string `json:"from"`
+	// To is the target path, or an absolute URL for redirects.
+	To string `json:"to"`
+	// Code is the status code of the redirect: 301, 302, 303, 307 or 308.
+	// Zero makes the rule an internal rewrite, which changes the
+	// request path and runs the route lookup again.
+	Code int `json:"code,omitempty"`
+}
+
+// Redirect responds to requests matching the from pattern with
+// a redirect to the target, using the status code provided.
+func (r *Router) Redirect(from, to string, code int) *Route {
+	return r.Rule(Rule{From: from, To: to, Code: code})
+}
+
+// Rewrite serves requests matching the from pattern as if they
+// were made for the target path.
+func (r *Router) Rewrite(from, to string) *Route {
+	return r.Rule(Rule{From: from, To: to})
+}
+
+// Rule registers a single redirect or rewrite rule,
+// and panics if the rule is invalid.
+func (r *Router) Rule(rule Rule) *Route {
+	method, handler, err := r.ruleHandler(rule)
+	if err != nil {
+		panic(err.Error())
+	}
+
+	return r.Handle(method, rule.From, handler)
+}
+
+// LoadRules registers the rules in order, and returns an error
+// describing the first rule that could not be registered.
+func (r *Router) LoadRules(rules []Rule) error {
+	for i, rule := range rules {
+		method, handler, err := r.ruleHandler(rule)
+		if err == nil {
+			_, err = r.TryHandle(method, rule.From, handler)
+		}
+
+		if err != nil {
+			return fmt.Errorf("rule #%d (%s -> %s): %w", i+1, rule.From, rule.To, err)
+		}
+	}
+
+	return nil
+}
+
+// ruleHandler validates the rule, and returns the method and
+// the handler of its route.
+func (r *Router) ruleHandler(rule Rule) (string, http.Handler, error) {
+	if err := rule.validate(); err != nil {
+		return "", nil, err
+	}
+
+	method := rule.Method
+	if method == "" {
+		method = MethodAny
+	}
+
+	if rule.Code == 0 {
+		return method, &rewriteHandler{router: r, to: rule.To}, nil
+	}
+
+	return method, &redirectHandler{to: rule.To, code: rule.Code}, nil
+}
+
+// validate checks the status code and that every parameter of the
+// target is defined by the pattern.
+func (rule Rule) validate() error {
+	switch rule.Code {
+	case 0, http.StatusMovedPermanently, http.StatusFound, http.StatusSeeOther,
+		http.StatusTemporaryRedirect, http.StatusPermanentRedirect:
+	default:
+		return fmt.Errorf("invalid redirect code %d for rule '%s'", rule.Code, rule.From)
+	}
+
+	if rule.Code == 0 && (rule.To == "" || rule.To[0] != '/') {
+		return fmt.Errorf("rewrite target must start with '/' in '%s'", rule.To)
+	}
+
+	defined := make(map[string]bool)
+	for _, name := range patternParams(rule.From) {
+		defined[name] = true
+	}
+
+	for _, name := range patternParams(rule.To) {
+		if !defined[name] {
+			return fmt.Errorf("parameter '%s' of '%s' is not defined in '%s'", name, rule.To, rule.From)
+		}
+	}
+
+	return nil
+}
+
+type redirectHandler struct {
+	to   string
+	code int
+}
+
+func (h *redirectHandler) ServeHTTP(w http.ResponseWriter, req *http.Request) {
+	ps, _ := params.FromContext(req.Context())
+
+	target := expandPattern(h.to, ps)
+	if req.URL.RawQuery != "" && !strings.Contains(target, "?") {
+		target += "?" + req.URL.RawQuery
+	}
+
+	http.Redirect(w, req, target, h.code)
+}
+
+type rewriteHandler struct {
+	router *Router
+	to     string
+}
+
+func (h *rewriteHandler) ServeHTTP(w http.ResponseWriter, req *http.Request) {
+	rewrites, _ := req.Context().Value(rewriteKey).(int)
+	if rewrites >= maxRewrites {
+		http.Error(w, "too many rewrites", http.StatusInternalServerError)
+		return
+	}
+
+	ps, _ := params.FromContext(req.Context())
+
+	target, err := url.Parse(expandPattern(h.to, ps))
+	if err != nil {
+		http.Error(w, http.StatusText(http.StatusInternalServerError), http.StatusInternalServerError)
+		return
+	}
+
+	rewritten := *req.URL
+	rewritten.Path = target.Path
+	rewritten.RawPath = target.RawPath
+	if target.RawQuery != "" {
+		rewritten.RawQuery = strings.Trim(target.RawQuery+"&"+req.URL.RawQuery, "&")
+	}
+
+	// The params of the matched rule must not leak into the
+	// route the request is rewritten to.
+	ctx := params.WithParams(req.Context(), nil)
+	ctx = context.WithValue(ctx, rewriteKey, rewrites+1)
+
+	req = req.WithContext(ctx)
+	req.URL = &rewritten
+
+	h.router.ServeHTTP(w, req)
+}
+
+// isPatternParam checks if the pattern has a parameter or
+// a wildcard at the index. Variables can only start a path
+// segment, so the ':' of an URL scheme is not a parameter.
+func isPatternParam(pattern string, i int) bool {
+	return (pattern[i] == ':' || pattern[i] == '*') && i > 0 && pattern[i-1] == '/'
+}
+
+// patternParams returns the names of all parameters and wildcards of the pattern.
+func patternParams(pattern string) []string {
+	var names []string
+
+	for i := 0; i < len(pattern); i++ {
+		if !isPatternParam(pattern, i) {
+			continue
+		}
+
+		end := strings.IndexAny(pattern[i:], "/?")
+		if end == -1 {
+			end = len(pattern) - i
+		}
+
+		names = append(names, pattern[i+1:i+end])
+		i += end - 1
+	}
+
+	return names
+}
+
+// expandPattern substitutes the parameters and wildcards of the pattern
+// with their escaped values, so a value can not add a query or a fragment
+// to the target, e.g. a%3Fx stays a%3Fx rather than starting a query.
+func expandPattern(pattern string, ps params.Params) string {
+	var expanded strings.Builder
+
+	for i := 0; i < len(pattern); i++ {
+		if !isPatternParam(pattern, i) {
+			expanded.WriteByte(pattern[i])
+			continue
+		}
+
+		end := strings.IndexAny(pattern[i:], "/?")
+		if end == -1 {
+			end = len(pattern) - i
+		}
+
+		// Param values never contain a '/', so they are escaped the same
+		// as the wildcard values, whichever syntax the target uses.
+		value, _ := ps.ByName(pattern[i+1 : i+end])
+		expanded.WriteString(escapeSegments(value))
+		i += end - 1
+	}
+
+	return expanded.String()
+}
+
+// escapeSegments escapes each segment of the path value, keeping the '/'.
+func escapeSegments(value string) string {
+	segments := strings.Split(value, "/")
+	for i, segment := range segments {
+		segments[i] = url.PathEscape(segment)
+	}
+
+	return strings.Join(segments, "/")
+}
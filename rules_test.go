@@ -0,0 +1,85 @@
+package hyper
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/bencicandrej/hyper-router/params"
+)
+
+func TestRouterRules(t *testing.T) {
+	router := NewRouter()
+	router.Get("/new/:id", http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		ps, _ := params.FromContext(r.Context())
+		id, _ := ps.ByName("id")
+		w.Write([]byte("new " + id + " " + r.URL.RawQuery))
+	}))
+
+	err := router.LoadRules([]Rule{
+		{From: "/old/:id", To: "/new/:id", Code: http.StatusMovedPermanently},
+		{From: "/external/*path", To: "https://example.com/:path", Code: http.StatusFound},
+		{From: "/files/*path", To: "/docs/*path", Code: http.StatusFound},
+		{From: "/legacy/:id", To: "/new/:id?legacy=1"},
+		{From: "/loop/a", To: "/loop/b"},
+		{From: "/loop/b", To: "/loop/a"},
+	})
+	if err != nil {
+		t.Fatalf("router.LoadRules(): got error %s", err)
+	}
+
+	tests := []struct {
+		path     string
+		code     int
+		location string
+		body     string
+	}{
+		{"/old/42?ref=mail", http.StatusMovedPermanently, "/new/42?ref=mail", ""},
+		{"/external/docs/intro", http.StatusFound, "https://example.com/docs/intro", ""},
+		{"/old/a%3Fx=1%23y", http.StatusMovedPermanently, "/new/a%3Fx=1%23y", ""},
+		{"/files/a%2Fb/c%3Fd%23e", http.StatusFound, "/docs/a/b/c%3Fd%23e", ""},
+		{"/legacy/a%3Fx=1", http.StatusOK, "", "new a?x=1 legacy=1"},
+		{"/legacy/7?page=2", http.StatusOK, "", "new 7 legacy=1&page=2"},
+		{"/loop/a", http.StatusInternalServerError, "", ""},
+	}
+
+	for _, test := range tests {
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, httptest.NewRequest(http.MethodGet, test.path, nil))
+
+		if w.Code != test.code {
+			t.Errorf("GET %s: got status %d, wanted %d", test.path, w.Code, test.code)
+		}
+
+		if location := w.Header().Get("Location"); location != test.location {
+			t.Errorf("GET %s: got location '%s', wanted '%s'", test.path, location, test.location)
+		}
+
+		if test.body != "" && w.Body.String() != test.body {
+			t.Errorf("GET %s: got body '%s', wanted '%s'", test.path, w.Body.String(), test.body)
+		}
+	}
+}
+
+func TestRouterLoadRulesErrors(t *testing.T) {
+	tests := []Rule{
+		{From: "/old/:id", To: "/new/:uid", Code: http.StatusMovedPermanently},
+		{From: "/old", To: "/new", Code: http.StatusOK},
+		{From: "/old", To: "new"},
+	}
+
+	for _, test := range tests {
+		if err := NewRouter().LoadRules([]Rule{test}); err == nil {
+			t.Errorf("router.LoadRules(%v): expected error, got none", test)
+		}
+	}
+
+	router := NewRouter()
+	err := router.LoadRules([]Rule{
+		{From: "/old", To: "/new", Code: http.StatusFound},
+		{From: "/old", To: "/newer", Code: http.StatusFound},
+	})
+	if err == nil {
+		t.Error("router.LoadRules(): expected error for a duplicate rule, got none")
+	}
+}
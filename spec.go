@@ -0,0 +1,425 @@
+package hyper
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"os"
+	"regexp"
+	"sort"
+	"strings"
+)
+
+// spec is the part of an OpenAPI 3 document needed to register
+// and validate its operations.
+type spec struct {
+	OpenAPI    string                                `json:"openapi"`
+	Paths      map[string]map[string]json.RawMessage `json:"paths"`
+	Components specComponents                        `json:"components"`
+}
+
+type specComponents struct {
+	Schemas       map[string]*specSchema    `json:"schemas"`
+	Parameters    map[string]*specParameter `json:"parameters"`
+	RequestBodies map[string]*specBody      `json:"requestBodies"`
+}
+
+type specOperation struct {
+	OperationID string           `json:"operationId"`
+	Summary     string           `json:"summary"`
+	Description string           `json:"description"`
+	Tags        []string         `json:"tags"`
+	Deprecated  bool             `json:"deprecated"`
+	Parameters  []*specParameter `json:"parameters"`
+	RequestBody *specBody        `json:"requestBody"`
+}
+
+type specParameter struct {
+	Ref      string      `json:"$ref"`
+	Name     string      `json:"name"`
+	In       string      `json:"in"`
+	Required bool        `json:"required"`
+	Schema   *specSchema `json:"schema"`
+}
+
+type specBody struct {
+	Ref      string                   `json:"$ref"`
+	Required bool                     `json:"required"`
+	Content  map[string]specMediaType `json:"content"`
+}
+
+type specMediaType struct {
+	Schema *specSchema `json:"schema"`
+}
+
+// specOperationMethods are the operation keys of an OpenAPI path item.
+var specOperationMethods = map[string]string{
+	"get":     http.MethodGet,
+	"head":    http.MethodHead,
+	"post":    http.MethodPost,
+	"put":     http.MethodPut,
+	"patch":   http.MethodPatch,
+	"delete":  http.MethodDelete,
+	"options": http.MethodOptions,
+	"trace":   http.MethodTrace,
+}
+
+// DefaultMaxBodySize is the size limit of the request bodies validated
+// against a JSON schema by the routes of router.LoadOpenAPI, unless
+// router.MaxBodySize is set.
+const DefaultMaxBodySize = 1 << 20
+
+// LoadOpenAPI registers the operations of the OpenAPI 3 document in the
+// JSON file, binding each operationId to the handler of the same name.
+//
+// Each route validates its requests against the document: the path
+// params, query params and headers against their schemas, and the JSON
+// body against the schema of the request body. Invalid requests are
+// rejected with 400 and a JSON list of the problems found, and bodies
+// larger than router.MaxBodySize with 413. The bodies without a JSON
+// schema, e.g. file uploads, are passed to the handler unread.
+//
+// Both the OpenAPI 3.1 exclusiveMinimum and exclusiveMaximum numbers
+// and the OpenAPI 3.0 booleans are supported.
+//
+// LoadOpenAPI returns an error, without registering any route, if the
+// document is invalid, a handler is missing for any of the operations,
+// or any of the operations conflicts with a route of the router.
+func (r *Router) LoadOpenAPI(file string, handlers map[string]http.Handler) error {
+	data, err := os.ReadFile(file)
+	if err != nil {
+		return err
+	}
+
+	s := &spec{}
+	if err := json.Unmarshal(data, s); err != nil {
+		return fmt.Errorf("openapi %s: %s", file, err)
+	}
+
+	if !strings.HasPrefix(s.OpenAPI, "3.") {
+		return fmt.Errorf("openapi %s: unsupported version '%s'", file, s.OpenAPI)
+	}
+
+	operations, err := s.operations()
+	if err != nil {
+		return fmt.Errorf("openapi %s: %s", file, err)
+	}
+
+	var missing []string
+	for _, op := range operations {
+		if handlers[op.OperationID] == nil {
+			missing = append(missing, op.OperationID)
+		}
+	}
+
+	if len(missing) > 0 {
+		return fmt.Errorf("openapi %s: missing handlers for operations %s", file, strings.Join(missing, ", "))
+	}
+
+	maxBodySize := r.MaxBodySize
+	if maxBodySize == 0 {
+		maxBodySize = DefaultMaxBodySize
+	}
+
+	// The operations are registered on a scratch router with the routes of
+	// the router first, so a conflict does not leave the router half filled.
+	scratch := NewRouter()
+	for _, route := range r.Routes() {
+		scratch.TryHandle(route.Method, route.Pattern, http.NotFoundHandler())
+	}
+
+	for _, op := range operations {
+		if _, err := scratch.TryHandle(op.method, op.pattern, http.NotFoundHandler()); err != nil {
+			return fmt.Errorf("openapi %s: operation %s: %s", file, op.OperationID, err)
+		}
+	}
+
+	for _, op := range operations {
+		op.maxBodySize = maxBodySize
+
+		if err := r.loadOperation(op, handlers[op.OperationID]); err != nil {
+			return fmt.Errorf("openapi %s: operation %s: %s", file, op.OperationID, err)
+		}
+	}
+
+	return nil
+}
+
+// loadOperation registers the route of the operation.
+func (r *Router) loadOperation(op *boundOperation, handler http.Handler) error {
+	route, err := r.TryHandle(op.method, op.pattern, handler, op.validate)
+	if err != nil {
+		return err
+	}
+
+	route.Named(op.OperationID)
+	route.Operation.Summary = op.Summary
+	route.Operation.Description = op.Description
+	route.Operation.Tags = op.Tags
+	route.Operation.Deprecated = op.Deprecated
+
+	return nil
+}
+
+// boundOperation is an operation of the document, with its
+// references resolved and its path converted to a route pattern.
+type boundOperation struct {
+	*specOperation
+
+	method  string
+	pattern string
+	body    *specSchema
+
+	// maxBodySize is the size limit of the request body.
+	maxBodySize int64
+
+	spec *spec
+}
+
+// operations returns the operations of the document, ordered
+// by their path and method.
+func (s *spec) operations() ([]*boundOperation, error) {
+	paths := make([]string, 0, len(s.Paths))
+	for path := range s.Paths {
+		paths = append(paths, path)
+	}
+
+	sort.Strings(paths)
+
+	seen := make(map[string]string)
+
+	var operations []*boundOperation
+	for _, path := range paths {
+		pattern, err := specPattern(path)
+		if err != nil {
+			return nil, err
+		}
+
+		item := s.Paths[path]
+
+		var shared []*specParameter
+		if raw, ok := item["parameters"]; ok {
+			if err := json.Unmarshal(raw, &shared); err != nil {
+				return nil, fmt.Errorf("parameters of %s: %s", path, err)
+			}
+		}
+
+		keys := make([]string, 0, len(item))
+		for key := range item {
+			if _, ok := specOperationMethods[key]; ok {
+				keys = append(keys, key)
+			}
+		}
+
+		sort.Strings(keys)
+
+		for _, key := range keys {
+			op := &boundOperation{
+				specOperation: &specOperation{},
+
+				method:  specOperationMethods[key],
+				pattern: pattern,
+
+				spec: s,
+			}
+
+			if err := json.Unmarshal(item[key], op.specOperation); err != nil {
+				return nil, fmt.Errorf("%s %s: %s", key, path, err)
+			}
+
+			if op.OperationID == "" {
+				return nil, fmt.Errorf("%s %s: missing operationId", key, path)
+			}
+
+			if other, ok := seen[op.OperationID]; ok {
+				return nil, fmt.Errorf("%s %s: operationId %s is already used by %s", key, path, op.OperationID, other)
+			}
+
+			seen[op.OperationID] = key + " " + path
+
+			if err := op.resolve(shared); err != nil {
+				return nil, fmt.Errorf("operation %s: %s", op.OperationID, err)
+			}
+
+			operations = append(operations, op)
+		}
+	}
+
+	return operations, nil
+}
+
+// resolve replaces the references of the parameters and the request
+// body with their components, and merges in the path item parameters
+// the operation does not override.
+func (op *boundOperation) resolve(shared []*specParameter) error {
+	parameters := make([]*specParameter, 0, len(shared)+len(op.Parameters))
+	overridden := make(map[string]bool)
+
+	for _, parameter := range op.Parameters {
+		resolved, err := op.spec.parameter(parameter)
+		if err != nil {
+			return err
+		}
+
+		overridden[resolved.In+" "+resolved.Name] = true
+		parameters = append(parameters, resolved)
+	}
+
+	for _, parameter := range shared {
+		resolved, err := op.spec.parameter(parameter)
+		if err != nil {
+			return err
+		}
+
+		if !overridden[resolved.In+" "+resolved.Name] {
+			parameters = append(parameters, resolved)
+		}
+	}
+
+	for _, parameter := range parameters {
+		if err := op.spec.prepareSchema(parameter.Schema, make(map[string]bool)); err != nil {
+			return err
+		}
+	}
+
+	op.Parameters = parameters
+
+	if op.RequestBody == nil {
+		return nil
+	}
+
+	if ref := op.RequestBody.Ref; ref != "" {
+		name := strings.TrimPrefix(ref, "#/components/requestBodies/")
+		if op.spec.Components.RequestBodies[name] == nil {
+			return fmt.Errorf("unresolved reference '%s'", ref)
+		}
+
+		op.RequestBody = op.spec.Components.RequestBodies[name]
+	}
+
+	for contentType, media := range op.RequestBody.Content {
+		if isJSONContentType(contentType) {
+			op.body = media.Schema
+		}
+	}
+
+	return op.spec.prepareSchema(op.body, make(map[string]bool))
+}
+
+func (s *spec) parameter(parameter *specParameter) (*specParameter, error) {
+	if parameter.Ref == "" {
+		return parameter, nil
+	}
+
+	name := strings.TrimPrefix(parameter.Ref, "#/components/parameters/")
+	if s.Components.Parameters[name] == nil {
+		return nil, fmt.Errorf("unresolved reference '%s'", parameter.Ref)
+	}
+
+	return s.Components.Parameters[name], nil
+}
+
+// prepareSchema checks that all schema references can be resolved,
+// compiles the patterns of the schema, and converts the OpenAPI 3.0
+// exclusive bounds to the OpenAPI 3.1 ones.
+func (s *spec) prepareSchema(schema *specSchema, visited map[string]bool) error {
+	if schema == nil {
+		return nil
+	}
+
+	if schema.Ref != "" {
+		if visited[schema.Ref] {
+			return nil
+		}
+
+		visited[schema.Ref] = true
+
+		resolved, err := s.schema(schema)
+		if err != nil {
+			return err
+		}
+
+		return s.prepareSchema(resolved, visited)
+	}
+
+	if schema.Pattern != "" && schema.pattern == nil {
+		pattern, err := regexp.Compile(schema.Pattern)
+		if err != nil {
+			return fmt.Errorf("invalid pattern '%s': %s", schema.Pattern, err)
+		}
+
+		schema.pattern = pattern
+	}
+
+	if schema.ExclusiveMinimum.Exclusive && schema.Minimum != nil {
+		schema.ExclusiveMinimum = exclusiveBound{Value: schema.Minimum}
+		schema.Minimum = nil
+	}
+
+	if schema.ExclusiveMaximum.Exclusive && schema.Maximum != nil {
+		schema.ExclusiveMaximum = exclusiveBound{Value: schema.Maximum}
+		schema.Maximum = nil
+	}
+
+	children := []*specSchema{schema.Items, schema.AdditionalProperties.Schema}
+	for _, property := range schema.Properties {
+		children = append(children, property)
+	}
+
+	children = append(children, schema.AllOf...)
+	children = append(children, schema.AnyOf...)
+	children = append(children, schema.OneOf...)
+
+	for _, child := range children {
+		if err := s.prepareSchema(child, visited); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// schema resolves the schema reference.
+func (s *spec) schema(schema *specSchema) (*specSchema, error) {
+	for depth := 0; schema.Ref != ""; depth++ {
+		name := strings.TrimPrefix(schema.Ref, "#/components/schemas/")
+		if s.Components.Schemas[name] == nil || depth > len(s.Components.Schemas) {
+			return nil, fmt.Errorf("unresolved reference '%s'", schema.Ref)
+		}
+
+		schema = s.Components.Schemas[name]
+	}
+
+	return schema, nil
+}
+
+// specPattern converts the OpenAPI path template into the route
+// pattern, e.g. /users/{id} to /users/:id.
+func specPattern(path string) (string, error) {
+	if path == "" || path[0] != '/' {
+		return "", fmt.Errorf("path '%s' must start with '/'", path)
+	}
+
+	segments := strings.Split(path, "/")
+	for i, segment := range segments {
+		if !strings.ContainsAny(segment, "{}") {
+			continue
+		}
+
+		name := strings.TrimSuffix(strings.TrimPrefix(segment, "{"), "}")
+		if len(name) != len(segment)-2 || name == "" || strings.ContainsAny(name, "{}") {
+			return "", fmt.Errorf("path '%s': templates must span a whole segment", path)
+		}
+
+		segments[i] = ":" + name
+	}
+
+	return strings.Join(segments, "/"), nil
+}
+
+// isJSONContentType checks for application/json and the +json media types.
+func isJSONContentType(contentType string) bool {
+	mediaType := strings.TrimSpace(strings.ToLower(strings.SplitN(contentType, ";", 2)[0]))
+
+	return mediaType == "application/json" || strings.HasSuffix(mediaType, "+json")
+}
@@ -0,0 +1,352 @@
+package hyper
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+const petSpec = `{
+  "openapi": "3.1.0",
+  "info": {"title": "Pets", "version": "1.0"},
+  "paths": {
+    "/pets": {
+      "get": {
+        "operationId": "listPets",
+        "tags": ["pets"],
+        "parameters": [
+          {"name": "limit", "in": "query", "schema": {"type": "integer", "minimum": 1, "maximum": 100}},
+          {"name": "X-Tenant", "in": "header", "required": true, "schema": {"type": "string"}}
+        ]
+      },
+      "post": {
+        "operationId": "createPet",
+        "requestBody": {"$ref": "#/components/requestBodies/Pet"}
+      }
+    },
+    "/pets/{id}": {
+      "parameters": [{"$ref": "#/components/parameters/PetID"}],
+      "get": {"operationId": "showPet"}
+    }
+  },
+  "components": {
+    "parameters": {
+      "PetID": {"name": "id", "in": "path", "required": true, "schema": {"type": "integer"}}
+    },
+    "requestBodies": {
+      "Pet": {"required": true, "content": {"application/json": {"schema": {"$ref": "#/components/schemas/Pet"}}}}
+    },
+    "schemas": {
+      "Pet": {
+        "type": "object",
+        "required": ["name"],
+        "additionalProperties": false,
+        "properties": {
+          "name": {"type": "string", "minLength": 1, "pattern": "^[a-z]+$"},
+          "kind": {"enum": ["cat", "dog"]},
+          "tags": {"type": "array", "items": {"type": "string"}}
+        }
+      }
+    }
+  }
+}`
+
+func writeSpec(t *testing.T, spec string) string {
+	file := filepath.Join(t.TempDir(), "openapi.json")
+	if err := os.WriteFile(file, []byte(spec), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	return file
+}
+
+func TestLoadOpenAPI(t *testing.T) {
+	router := NewRouter()
+
+	err := router.LoadOpenAPI(writeSpec(t, petSpec), map[string]http.Handler{
+		"listPets":  methodHandler("list"),
+		"createPet": methodHandler("create"),
+		"showPet":   methodHandler("show"),
+	})
+	if err != nil {
+		t.Fatalf("LoadOpenAPI(): got error %s", err)
+	}
+
+	if match, ok := router.Lookup(http.MethodGet, "/pets/1"); !ok || match.Name != "showPet" || match.Pattern != "/pets/:id" {
+		t.Errorf("LoadOpenAPI(): got match %+v", match)
+	}
+
+	if tags := router.Routes()[0].Operation.Tags; len(tags) != 1 || tags[0] != "pets" {
+		t.Errorf("LoadOpenAPI(): got tags %v", tags)
+	}
+
+	tests := []struct {
+		method   string
+		path     string
+		header   string
+		body     string
+		code     int
+		problems []string
+	}{
+		{http.MethodGet, "/pets?limit=10", "t1", "", http.StatusOK, nil},
+		{http.MethodGet, "/pets?limit=0", "t1", "", http.StatusBadRequest, []string{"query limit: must be at least 1"}},
+		{http.MethodGet, "/pets?limit=ten", "", "", http.StatusBadRequest, []string{"query limit: must be of type integer", "header X-Tenant: is required"}},
+		{http.MethodGet, "/pets/1", "", "", http.StatusOK, nil},
+		{http.MethodGet, "/pets/rex", "", "", http.StatusBadRequest, []string{"path id: must be of type integer"}},
+		{http.MethodPost, "/pets", "", `{"name": "rex", "kind": "dog", "tags": ["good"]}`, http.StatusOK, nil},
+		{http.MethodPost, "/pets", "", "", http.StatusBadRequest, []string{"body : is required"}},
+		{http.MethodPost, "/pets", "", `{"kind": "cow", "tags": [1], "age": 3}`, http.StatusBadRequest, []string{
+			"body : missing required property name",
+			"body : unknown property age",
+			"body : kind: must be one of the allowed values",
+			"body : tags[0]: must be of type string",
+		}},
+		{http.MethodPost, "/pets", "", `{"name": "Rex"}`, http.StatusBadRequest, []string{"body : name: must match the pattern ^[a-z]+$"}},
+	}
+
+	for _, test := range tests {
+		req := httptest.NewRequest(test.method, test.path, strings.NewReader(test.body))
+		req.Header.Set("Content-Type", "application/json")
+		if test.header != "" {
+			req.Header.Set("X-Tenant", test.header)
+		}
+
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+
+		if w.Code != test.code {
+			t.Errorf("%s %s: got code %d, wanted %d, body %s", test.method, test.path, w.Code, test.code, w.Body)
+			continue
+		}
+
+		if test.code != http.StatusBadRequest {
+			continue
+		}
+
+		var response struct {
+			Problems []validationProblem `json:"problems"`
+		}
+		json.Unmarshal(w.Body.Bytes(), &response)
+
+		var problems []string
+		for _, problem := range response.Problems {
+			problems = append(problems, problem.In+" "+problem.Name+": "+problem.Message)
+		}
+
+		if strings.Join(problems, "\n") != strings.Join(test.problems, "\n") {
+			t.Errorf("%s %s: got problems\n%s\nwanted\n%s", test.method, test.path,
+				strings.Join(problems, "\n"), strings.Join(test.problems, "\n"))
+		}
+	}
+}
+
+func TestLoadOpenAPIExclusiveBounds(t *testing.T) {
+	specs := map[string]string{
+		"3.0": `{"openapi": "3.0.3", "paths": {"/pets": {"get": {"operationId": "listPets", "parameters": [
+			{"name": "weight", "in": "query", "schema": {"type": "number",
+				"minimum": 0, "exclusiveMinimum": true, "maximum": 100, "exclusiveMaximum": true}},
+			{"name": "age", "in": "query", "schema": {"type": "number", "minimum": 0, "exclusiveMinimum": false}}]}}}}`,
+		"3.1": `{"openapi": "3.1.0", "paths": {"/pets": {"get": {"operationId": "listPets", "parameters": [
+			{"name": "weight", "in": "query", "schema": {"type": "number", "exclusiveMinimum": 0, "exclusiveMaximum": 100}},
+			{"name": "age", "in": "query", "schema": {"type": "number", "minimum": 0}}]}}}}`,
+	}
+
+	tests := []struct {
+		query string
+		code  int
+	}{
+		{"weight=0.5&age=0", http.StatusOK},
+		{"weight=0", http.StatusBadRequest},
+		{"weight=100", http.StatusBadRequest},
+		{"weight=99.5", http.StatusOK},
+		{"age=-1", http.StatusBadRequest},
+	}
+
+	for version, spec := range specs {
+		router := NewRouter()
+
+		if err := router.LoadOpenAPI(writeSpec(t, spec), map[string]http.Handler{"listPets": emptyHandler}); err != nil {
+			t.Fatalf("LoadOpenAPI(%s): got error %s", version, err)
+		}
+
+		for _, test := range tests {
+			w := httptest.NewRecorder()
+			router.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/pets?"+test.query, nil))
+
+			if w.Code != test.code {
+				t.Errorf("%s GET /pets?%s: got code %d, wanted %d, body %s", version, test.query, w.Code, test.code, w.Body)
+			}
+		}
+	}
+}
+
+func TestLoadOpenAPINumbers(t *testing.T) {
+	spec := `{"openapi": "3.1.0", "paths": {"/pets": {"get": {"operationId": "listPets", "parameters": [
+		{"name": "age", "in": "query", "schema": {"type": "number", "minimum": 0}}]}}}}`
+
+	router := NewRouter()
+
+	if err := router.LoadOpenAPI(writeSpec(t, spec), map[string]http.Handler{"listPets": emptyHandler}); err != nil {
+		t.Fatalf("LoadOpenAPI(): got error %s", err)
+	}
+
+	tests := []struct {
+		age  string
+		code int
+	}{
+		{"1", http.StatusOK},
+		{"0.5", http.StatusOK},
+		{"1e2", http.StatusOK},
+		{"-0", http.StatusOK},
+		{"NaN", http.StatusBadRequest},
+		{"Inf", http.StatusBadRequest},
+		{"%2BInf", http.StatusBadRequest},
+		{"1_0", http.StatusBadRequest},
+		{"0x1", http.StatusBadRequest},
+		{"%2B1", http.StatusBadRequest},
+		{"01", http.StatusBadRequest},
+		{"1e400", http.StatusBadRequest},
+	}
+
+	for _, test := range tests {
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/pets?age="+test.age, nil))
+
+		if w.Code != test.code {
+			t.Errorf("GET /pets?age=%s: got code %d, wanted %d, body %s", test.age, w.Code, test.code, w.Body)
+		}
+	}
+}
+
+func TestLoadOpenAPIMaxBodySize(t *testing.T) {
+	router := NewRouter()
+	router.MaxBodySize = 32
+
+	err := router.LoadOpenAPI(writeSpec(t, petSpec), map[string]http.Handler{
+		"listPets":  emptyHandler,
+		"createPet": methodHandler("create"),
+		"showPet":   emptyHandler,
+	})
+	if err != nil {
+		t.Fatalf("LoadOpenAPI(): got error %s", err)
+	}
+
+	tests := []struct {
+		body string
+		code int
+	}{
+		{`{"name": "rex"}`, http.StatusOK},
+		{`{"name": "rex", "tags": ["good", "very good"]}`, http.StatusRequestEntityTooLarge},
+	}
+
+	for _, test := range tests {
+		req := httptest.NewRequest(http.MethodPost, "/pets", strings.NewReader(test.body))
+		req.Header.Set("Content-Type", "application/json")
+
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+
+		if w.Code != test.code {
+			t.Errorf("POST /pets %s: got code %d, wanted %d, body %s", test.body, w.Code, test.code, w.Body)
+		}
+	}
+}
+
+func TestLoadOpenAPIUnvalidatedBody(t *testing.T) {
+	spec := `{"openapi": "3.1.0", "paths": {"/files": {"post": {"operationId": "uploadFile",
+		"requestBody": {"required": true, "content": {"application/octet-stream": {}}}}}}}`
+
+	router := NewRouter()
+
+	err := router.LoadOpenAPI(writeSpec(t, spec), map[string]http.Handler{
+		"uploadFile": http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			data, _ := io.ReadAll(r.Body)
+			fmt.Fprint(w, len(data))
+		}),
+	})
+	if err != nil {
+		t.Fatalf("LoadOpenAPI(): got error %s", err)
+	}
+
+	upload := strings.Repeat("x", 2<<20)
+
+	tests := []struct {
+		name string
+		body io.Reader
+		code int
+		want string
+	}{
+		{"2 MiB", strings.NewReader(upload), http.StatusOK, "2097152"},
+		{"unknown length", io.MultiReader(strings.NewReader(upload)), http.StatusOK, "2097152"},
+		{"empty", nil, http.StatusBadRequest, ""},
+		{"empty of unknown length", io.MultiReader(), http.StatusBadRequest, ""},
+	}
+
+	for _, test := range tests {
+		req := httptest.NewRequest(http.MethodPost, "/files", test.body)
+		req.Header.Set("Content-Type", "application/octet-stream")
+
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+
+		if w.Code != test.code {
+			t.Errorf("POST /files (%s): got code %d, wanted %d, body %s", test.name, w.Code, test.code, w.Body)
+		}
+
+		if test.code == http.StatusOK && w.Body.String() != test.want {
+			t.Errorf("POST /files (%s): got body %s, wanted %s", test.name, w.Body, test.want)
+		}
+	}
+}
+
+func TestLoadOpenAPIErrors(t *testing.T) {
+	tests := []struct {
+		spec string
+		err  string
+	}{
+		{petSpec, "missing handlers for operations createPet, showPet"},
+		{`{"openapi": "2.0"}`, "unsupported version '2.0'"},
+		{`{"openapi": "3.0.3", "paths": {"/pets": {"get": {}}}}`, "get /pets: missing operationId"},
+		{`{"openapi": "3.0.3", "paths": {"/pets/{id}.json": {"get": {"operationId": "listPets"}}}}`, "templates must span a whole segment"},
+		{`{"openapi": "3.0.3", "paths": {"/pets": {"get": {"operationId": "listPets", "parameters": [
+			{"name": "q", "in": "query", "schema": {"$ref": "#/components/schemas/Missing"}}]}}}}`, "unresolved reference '#/components/schemas/Missing'"},
+	}
+
+	for _, test := range tests {
+		router := NewRouter()
+
+		err := router.LoadOpenAPI(writeSpec(t, test.spec), map[string]http.Handler{"listPets": emptyHandler})
+		if err == nil || !strings.Contains(err.Error(), test.err) {
+			t.Errorf("LoadOpenAPI(): got error %v, wanted %q", err, test.err)
+		}
+
+		if len(router.Routes()) != 0 {
+			t.Errorf("LoadOpenAPI(): registered routes on error")
+		}
+	}
+}
+
+func TestLoadOpenAPIConflict(t *testing.T) {
+	router := NewRouter()
+	router.Get("/pets/:petId", emptyHandler)
+
+	err := router.LoadOpenAPI(writeSpec(t, petSpec), map[string]http.Handler{
+		"listPets":  emptyHandler,
+		"createPet": emptyHandler,
+		"showPet":   emptyHandler,
+	})
+
+	if want := "operation showPet: handler for route '/pets/:id' already exists"; err == nil || !strings.Contains(err.Error(), want) {
+		t.Errorf("LoadOpenAPI(): got error %v, wanted %q", err, want)
+	}
+
+	if routes := router.Routes(); len(routes) != 1 {
+		t.Errorf("LoadOpenAPI(): got %d routes after a conflict, wanted only the registered one", len(routes))
+	}
+}
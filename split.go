@@ -0,0 +1,135 @@
+package hyper
+
+import (
+	"context"
+	"fmt"
+	"hash/fnv"
+	"math/rand"
+	"net/http"
+)
+
+var variantKey ctxKey = 3
+
+// Variant is a single handler of a Split, with its share of the traffic.
+type Variant struct {
+	// Name identifies the variant, and is available in the
+	// request context through the VariantFromContext function.
+	Name string
+	// Weight is the share of the traffic, relative to the
+	// weights of the other variants.
+	Weight int
+	// Handler serves the requests assigned to the variant.
+	Handler http.Handler
+}
+
+// Split is a http.Handler that splits the traffic of a single route
+// between several variants of the handler, e.g. 95/5 between the
+// current and the canary implementation:
+//
+//	router.Get("/checkout", hyper.NewSplit(
+//		hyper.Variant{Name: "stable", Weight: 95, Handler: checkout},
+//		hyper.Variant{Name: "canary", Weight: 5, Handler: newCheckout},
+//	).StickyCookie("session"))
+//
+// Requests are assigned randomly, unless the split is sticky, in which
+// case the same cookie or header value is always assigned the same variant.
+type Split struct {
+	variants []Variant
+	total    int
+
+	cookie string
+	header string
+}
+
+// NewSplit creates a Split of the variants, and panics
+// if the weights are negative or all zero.
+func NewSplit(variants ...Variant) *Split {
+	split := &Split{
+		variants: append([]Variant{}, variants...),
+	}
+
+	for _, variant := range variants {
+		if variant.Weight < 0 {
+			panic(fmt.Sprintf("weight of the variant '%s' must not be negative", variant.Name))
+		}
+
+		if variant.Handler == nil {
+			panic(fmt.Sprintf("handler of the variant '%s' must not be nil", variant.Name))
+		}
+
+		split.total += variant.Weight
+	}
+
+	if split.total == 0 {
+		panic("split must have at least one variant with a positive weight")
+	}
+
+	return split
+}
+
+// StickyCookie assigns the variant by the hash of the cookie value.
+func (split *Split) StickyCookie(name string) *Split {
+	split.cookie = name
+
+	return split
+}
+
+// StickyHeader assigns the variant by the hash of the header value.
+// The header is used only if the sticky cookie is not present.
+func (split *Split) StickyHeader(name string) *Split {
+	split.header = name
+
+	return split
+}
+
+// ServeHTTP serves the request with the assigned variant,
+// storing the variant name in the request context.
+func (split *Split) ServeHTTP(w http.ResponseWriter, req *http.Request) {
+	variant := split.choose(split.point(req))
+
+	variant.Handler.ServeHTTP(w, req.WithContext(context.WithValue(req.Context(), variantKey, variant.Name)))
+}
+
+// point returns a number in the [0, total) range, derived from the
+// sticky key of the request or chosen randomly if there is none.
+func (split *Split) point(req *http.Request) int {
+	key := ""
+	if split.cookie != "" {
+		if cookie, err := req.Cookie(split.cookie); err == nil {
+			key = cookie.Value
+		}
+	}
+
+	if key == "" && split.header != "" {
+		key = req.Header.Get(split.header)
+	}
+
+	if key == "" {
+		return rand.Intn(split.total)
+	}
+
+	hash := fnv.New32a()
+	hash.Write([]byte(key))
+
+	return int(hash.Sum32() % uint32(split.total))
+}
+
+// choose returns the variant covering the point.
+func (split *Split) choose(point int) Variant {
+	for _, variant := range split.variants {
+		if point < variant.Weight {
+			return variant
+		}
+
+		point -= variant.Weight
+	}
+
+	return split.variants[len(split.variants)-1]
+}
+
+// VariantFromContext extracts the name of the Split variant serving the request.
+func VariantFromContext(ctx context.Context) (string, bool) {
+	name, ok := ctx.Value(variantKey).(string)
+
+	return name, ok
+}
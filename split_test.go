@@ -0,0 +1,49 @@
+package hyper
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestSplit(t *testing.T) {
+	variantName := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		name, _ := VariantFromContext(r.Context())
+		w.Write([]byte(name))
+	})
+
+	router := NewRouter()
+	router.Get("/checkout", NewSplit(
+		Variant{Name: "stable", Weight: 90, Handler: variantName},
+		Variant{Name: "canary", Weight: 10, Handler: variantName},
+		Variant{Name: "disabled", Weight: 0, Handler: variantName},
+	).StickyHeader("X-User"))
+
+	counts := make(map[string]int)
+	for i := 0; i < 1000; i++ {
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/checkout", nil))
+		counts[w.Body.String()]++
+	}
+
+	if counts["stable"] < 800 || counts["canary"] < 40 || counts["disabled"] > 0 {
+		t.Errorf("GET /checkout: got distribution %v, wanted about 90/10", counts)
+	}
+
+	var sticky string
+	for i := 0; i < 20; i++ {
+		req := httptest.NewRequest(http.MethodGet, "/checkout", nil)
+		req.Header.Set("X-User", "user-42")
+
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+
+		if sticky == "" {
+			sticky = w.Body.String()
+		}
+
+		if w.Body.String() != sticky {
+			t.Fatalf("GET /checkout (X-User: user-42): got variant '%s', wanted sticky '%s'", w.Body.String(), sticky)
+		}
+	}
+}
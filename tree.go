@@ -12,8 +12,15 @@ import (
 )
 
 type node struct {
-	label   nodeLabel
-	handler http.Handler
+	label nodeLabel
+	// handlers holds the handlers registered for the node, indexed
+	// by the method index. Nodes without handlers hold a nil table.
+	handlers methodHandlers
+	// methods holds the method indices of the routes of the node and
+	// its descendants. The routes of different methods may use different
+	// children at the same position, e.g. /users/:id for GET and
+	// /users/search for POST, so a lookup follows the children of its method.
+	methods methodSet
 
 	parent *node
 	// children represents an array of child nodes, ordered by priority:
@@ -21,26 +28,71 @@ type node struct {
 	children []*node
 }
 
-func (tree node) getHandler(ctx context.Context, label nodeLabel) (http.Handler, context.Context) {
+// methodHandlers is a method-indexed table of the node handlers.
+// The index 0 is reserved for the handler of the MethodAny routes.
+type methodHandlers []http.Handler
+
+// get returns the handler registered for the method index.
+func (handlers methodHandlers) get(method int) http.Handler {
+	if method < len(handlers) {
+		return handlers[method]
+	}
+
+	return nil
+}
+
+// methodSet is a bit set of the method indices.
+type methodSet []uint64
+
+// has checks if the method index is in the set.
+func (set methodSet) has(method int) bool {
+	word := method / 64
+
+	return word < len(set) && set[word]&(1<<uint(method%64)) != 0
+}
+
+// with returns a copy of the set with the method index added,
+// so the nodes split from each other never share the bits.
+func (set methodSet) with(method int) methodSet {
+	words := len(set)
+	if method/64 >= words {
+		words = method/64 + 1
+	}
+
+	result := make(methodSet, words)
+	copy(result, set)
+	result[method/64] |= 1 << uint(method%64)
+
+	return result
+}
+
+// getHandler returns the handler registered for the method index
+// and the path, and the context carrying the params of the path.
+func (tree node) getHandler(ctx context.Context, label nodeLabel, method int) (http.Handler, context.Context) {
 	if tree.isEmpty() {
 		return nil, ctx
 	}
 
 	if tree.isWildcard() {
-		return tree.handler, params.NewContext(ctx, string(tree.label)[1:], string(label))
+		return tree.handlers.get(method), params.NewContext(ctx, string(tree.label)[1:], string(label))
 	}
 
 	if tree.isParameter() {
 		paramEnd, finishedBeforeEnd := label.getEndOfVariable()
+		// Parameters never match an empty path segment.
+		if paramEnd == 0 {
+			return nil, ctx
+		}
+
 		if !finishedBeforeEnd {
-			return tree.handler, params.NewContext(ctx, string(tree.label)[1:], string(label))
+			return tree.handlers.get(method), params.NewContext(ctx, string(tree.label)[1:], string(label))
 		}
 
+		// The param is added before the lookup continues in the child,
+		// so the params are ordered the same as in the path.
 		for _, child := range tree.children {
-			if child.supports(label[paramEnd:]) {
-				handler, ctx := child.getHandler(ctx, label[paramEnd:])
-
-				return handler, params.NewContext(ctx, string(tree.label)[1:], string(label))
+			if child.methods.has(method) && child.supports(label[paramEnd:]) {
+				return child.getHandler(params.NewContext(ctx, string(tree.label)[1:], string(label[:paramEnd])), label[paramEnd:], method)
 			}
 		}
 
@@ -50,13 +102,25 @@ func (tree node) getHandler(ctx context.Context, label nodeLabel) (http.Handler,
 	// node is static
 	if match, fullMatch := tree.matches(label); match {
 		if fullMatch {
-			return tree.handler, ctx
+			if handler := tree.handlers.get(method); handler != nil {
+				return handler, ctx
+			}
+
+			// An empty catch-all, e.g. /static/ for /static/*filepath,
+			// is served by the wildcard child with an empty value.
+			for _, child := range tree.children {
+				if child.isWildcard() && child.methods.has(method) {
+					return child.getHandler(ctx, "", method)
+				}
+			}
+
+			return nil, ctx
 		}
 
 		treeLen := len(tree.label)
 		for _, child := range tree.children {
-			if child.supports(label[treeLen:]) {
-				return child.getHandler(ctx, label[treeLen:])
+			if child.methods.has(method) && child.supports(label[treeLen:]) {
+				return child.getHandler(ctx, label[treeLen:], method)
 			}
 		}
 	}
@@ -64,17 +128,58 @@ func (tree node) getHandler(ctx context.Context, label nodeLabel) (http.Handler,
 	return nil, ctx
 }
 
-// insert associates the new handler with the route provided,
-// and panics if encounters any anomalies.
+// insert associates the handler with the route and the method index
+// provided, and panics if the handler for the method already exists,
+// or the route conflicts with another route of the same method.
+func (tree *node) insert(label nodeLabel, method int, handler http.Handler) *node {
+	// Label must start with a '/', even if the tree is not empty.
+	if tree.parent == nil && !label.isValidRootLabel() {
+		panic(fmt.Sprintf("route '%s' must start with '/'", label))
+	}
+
+	// The wildcard is checked before any node is split or created, since
+	// insertNode finds a misplaced one only after adding the nodes before
+	// it, which would then conflict with the later routes.
+	if wildcardPos, ok := label.getWildcard(); ok {
+		if _, finishedBeforeEnd := label[wildcardPos:].getEndOfVariable(); finishedBeforeEnd {
+			panic(fmt.Sprintf("wildcard parameter must be the last element of the route '%s'", label))
+		}
+	}
+
+	n := tree.insertNode(label, method)
+
+	if n.handlers.get(method) != nil {
+		panic(fmt.Sprintf("handler for route '%s' already exists", n.path()))
+	}
+
+	if method >= len(n.handlers) {
+		handlers := make(methodHandlers, method+1)
+		copy(handlers, n.handlers)
+		n.handlers = handlers
+	}
+
+	n.handlers[method] = handler
+
+	for parent := n; parent != nil; parent = parent.parent {
+		parent.methods = parent.methods.with(method)
+	}
+
+	return n
+}
+
+// insertNode returns the node of the route provided, creating
+// it if needed, and panics if encounters any anomalies. Only the
+// children leading to the routes of the method can conflict with the
+// route, since the lookup follows the children of its method.
 //
 // Method flow:
 // #1) If the current node is empty, populate it and exit.
-// #2) If label and tree.label are equal, we match or panic if handler exists.
-// #3) If the prefix is equal to the label, we must split the node and associate the handler with the parent node.
+// #2) If label and tree.label are equal, we match.
+// #3) If the prefix is equal to the label, we must split the node and return the parent node.
 // #4) If the prefix < label && prefix < tree.label && prefix > 0, split and pass to new node.
 // #5) If the prefix is equal to the tree.label, we must create a new node, or pass insertion to a child
 // #6) If the prefix is equal to 0, we must panic
-func (tree *node) insert(label nodeLabel, handler http.Handler) *node {
+func (tree *node) insertNode(label nodeLabel, method int) *node {
 	// #1) If tree is empty populate the current element.
 	if tree.isEmpty() {
 		// Label must start with a '/'.
@@ -86,31 +191,32 @@ func (tree *node) insert(label nodeLabel, handler http.Handler) *node {
 			// Will never be 0 because a '/' is required to be first.
 			tree.label = label[:variablePos]
 
-			return tree.insert(label[variablePos:], handler)
+			return tree.insertNode(label[variablePos:], method)
 		}
 
 		tree.label = label
-		tree.handler = handler
 		return tree
 	}
 
-	if parameterPos, ok := label.getParameter(); ok {
+	// The first variable of the label decides the kind of the node,
+	// since a wildcard runs to the end of the route and may contain ':'.
+	variablePos, hasVariable := label.getVariable()
+
+	if parameterPos := variablePos; hasVariable && label[parameterPos] == ':' {
 		if parameterPos == 0 {
 			// Find end of parameter
 			parameterEnd, finishedBeforeEnd := label.getEndOfVariable()
 
-			if len(tree.children) > 1 {
+			if tree.conflicts(label[:parameterEnd], method) {
 				panic(fmt.Sprintf("handler for route '%s%s' already exists", tree.path(), label))
 			}
 
-			if len(tree.children) == 1 {
-				child := tree.children[0]
-
-				if child.label != label[:parameterEnd] || parameterEnd == len(label) {
-					panic(fmt.Sprintf("handler for route '%s%s' already exists", tree.path(), label))
+			if child := tree.variableChild(label[:parameterEnd]); child != nil {
+				if !finishedBeforeEnd {
+					return child
 				}
 
-				return child.insert(label[parameterEnd:], handler)
+				return child.insertNode(label[parameterEnd:], method)
 			}
 
 			newNode := node{
@@ -123,19 +229,18 @@ func (tree *node) insert(label nodeLabel, handler http.Handler) *node {
 			tree.children = append([]*node{&newNode}, tree.children...)
 
 			if finishedBeforeEnd {
-				return newNode.insert(label[parameterEnd:], handler)
+				return newNode.insertNode(label[parameterEnd:], method)
 			}
 
-			newNode.handler = handler
 			return &newNode
 		}
 
-		newNode := tree.insert(label[:parameterPos], nil)
+		newNode := tree.insertNode(label[:parameterPos], method)
 
-		return newNode.insert(label[parameterPos:], handler)
+		return newNode.insertNode(label[parameterPos:], method)
 	}
 
-	if wildcardPos, ok := label.getWildcard(); ok {
+	if wildcardPos := variablePos; hasVariable {
 		if wildcardPos == 0 {
 			if _, finishedBeforeEnd := label.getEndOfVariable(); finishedBeforeEnd {
 				panic(
@@ -143,13 +248,16 @@ func (tree *node) insert(label nodeLabel, handler http.Handler) *node {
 				)
 			}
 
-			if len(tree.children) > 0 {
+			if tree.conflicts(label, method) {
 				panic(fmt.Sprintf("handler for route '%s%s' already exists", tree.prefix(), label))
 			}
 
+			if child := tree.variableChild(label); child != nil {
+				return child
+			}
+
 			newNode := node{
-				label:   label,
-				handler: handler,
+				label: label,
 
 				parent: tree,
 			}
@@ -160,22 +268,14 @@ func (tree *node) insert(label nodeLabel, handler http.Handler) *node {
 			return &newNode
 		}
 
-		newNode := tree.insert(label[:wildcardPos], nil)
+		newNode := tree.insertNode(label[:wildcardPos], method)
 
-		return newNode.insert(label[wildcardPos:], handler)
+		return newNode.insertNode(label[wildcardPos:], method)
 	}
 
-	// #2) If we get a route match and the handler slot is free,
-	// we populate it and return.
-	if tree.label == label && tree.handler == nil {
-		tree.handler = handler
+	// #2) If we get a route match, the current node is the one.
+	if tree.label == label {
 		return tree
-	} else if tree.label == label && tree.handler != nil {
-		if handler == nil {
-			return tree
-		}
-
-		panic(fmt.Sprintf("handler for route '%s' already exists", label))
 	}
 
 	// Find the common prefix for the two labels.
@@ -183,10 +283,9 @@ func (tree *node) insert(label nodeLabel, handler http.Handler) *node {
 
 	// #3) If the tree.label is longer that the label and the label
 	// is contained inside the tree.label, we split the node and
-	// associate the handler to the current node.
+	// return the current node.
 	if tree.canSplit() && len(label) == prefixLength {
 		tree.split(prefixLength)
-		tree.handler = handler
 		return tree
 	}
 
@@ -199,17 +298,20 @@ func (tree *node) insert(label nodeLabel, handler http.Handler) *node {
 
 	for _, child := range tree.children {
 		if child.isWildcard() || child.isParameter() {
-			panic(fmt.Sprintf("handler for route '%s' already exists", label))
+			if child.methods.has(method) {
+				panic(fmt.Sprintf("handler for route '%s' already exists", label))
+			}
+
+			continue
 		}
 		if child.label[0] == label[prefixLength] {
-			return child.insert(label[prefixLength:], handler)
+			return child.insertNode(label[prefixLength:], method)
 		}
 	}
 
 	newNode := node{
-		label:   label[prefixLength:],
-		handler: handler,
-		parent:  tree,
+		label:  label[prefixLength:],
+		parent: tree,
 	}
 
 	tree.children = append(tree.children, &newNode)
@@ -217,6 +319,31 @@ func (tree *node) insert(label nodeLabel, handler http.Handler) *node {
 	return &newNode
 }
 
+// conflicts checks if a child other than the parameter or wildcard
+// with the label leads to the routes of the method, since the lookup
+// of the method could then follow either of them.
+func (tree *node) conflicts(label nodeLabel, method int) bool {
+	for _, child := range tree.children {
+		if child.label != label && child.methods.has(method) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// variableChild returns the parameter or wildcard child with the label,
+// which may lead only to the routes of the other methods so far.
+func (tree *node) variableChild(label nodeLabel) *node {
+	for _, child := range tree.children {
+		if child.label == label {
+			return child
+		}
+	}
+
+	return nil
+}
+
 // canSplit tests whether the current node can be divided into at least two nodes
 func (tree node) canSplit() bool {
 	return len(tree.label) > 1
@@ -226,15 +353,21 @@ func (tree node) canSplit() bool {
 // the length of which is specified by the splitting point.
 func (tree *node) split(splitPoint int) *node {
 	newNode := node{
-		label:   tree.label[splitPoint:],
-		handler: tree.handler,
+		label:    tree.label[splitPoint:],
+		handlers: tree.handlers,
+		methods:  tree.methods,
 
 		parent:   tree,
 		children: tree.children,
 	}
 
+	// The children now belong to the new node.
+	for _, child := range newNode.children {
+		child.parent = &newNode
+	}
+
 	tree.label = tree.label[:splitPoint]
-	tree.handler = nil
+	tree.handlers = nil
 	tree.children = []*node{&newNode}
 
 	return &newNode
@@ -280,7 +413,7 @@ func (tree node) exactlyMatches(label nodeLabel) bool {
 
 // isEmpty checks if the tree node is empty.
 func (tree node) isEmpty() bool {
-	return tree.label == "" && tree.handler == nil
+	return tree.label == "" && tree.handlers == nil
 }
 
 // isWildcard checks if the node is marked with
@@ -310,7 +443,7 @@ func (tree node) path() string {
 // String conforms to the fmt.Stringer interface,
 // so we can easily print out internal tree structure.
 func (tree node) String() string {
-	if tree.label == "" && tree.handler == nil {
+	if tree.isEmpty() {
 		return "NIL TREE"
 	}
 
@@ -329,14 +462,14 @@ func (tree node) string(offset int) string {
 
 	fmt.Fprintf(buff, "%s", tree.label)
 
-	if tree.handler != nil {
+	if tree.handlers != nil {
 		fmt.Fprint(buff, " ✓")
 	}
 
 	fmt.Fprintln(buff)
 
 	for _, child := range tree.children {
-		fmt.Fprintf(buff, child.string(offset+1))
+		fmt.Fprint(buff, child.string(offset+1))
 	}
 	return string(buff.Bytes())
 }
@@ -385,17 +518,6 @@ func (label nodeLabel) getWildcard() (index int, ok bool) {
 	return index, true
 }
 
-// getParameter returns an index of the parameter and a boolean
-// for signaling whether the parameter was found.
-func (label nodeLabel) getParameter() (index int, ok bool) {
-	index = strings.IndexByte(label.String(), byte(':'))
-	if index == -1 {
-		return 0, false
-	}
-
-	return index, true
-}
-
 // getVariable returns an index of the first parameter or wildcard
 // encountered, and a boolean for signaling whether there are any
 // variables in the label.
@@ -5,6 +5,8 @@ import (
 	"golang.org/x/net/context"
 	"net/http"
 	"testing"
+
+	"github.com/bencicandrej/hyper-router/params"
 )
 
 var emptyHandler = http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {})
@@ -12,7 +14,7 @@ var emptyHandler = http.HandlerFunc(func(w http.ResponseWriter, r *http.Request)
 func TestEmptyNodeTree(t *testing.T) {
 	tree := &node{}
 
-	handler, _ := tree.getHandler(context.Background(), "/route/404")
+	handler, _ := tree.getHandler(context.Background(), "/route/404", 0)
 
 	if handler != nil {
 		t.Errorf("node.getHandler('%s'): got %v, wanted nil", "/route/404", handler)
@@ -161,15 +163,15 @@ func TestNodeCanSplit(t *testing.T) {
 	}{
 		{
 			tree: &node{
-				label:   "/",
-				handler: emptyHandler,
+				label:    "/",
+				handlers: methodHandlers{emptyHandler},
 			},
 			want: false,
 		},
 		{
 			tree: &node{
-				label:   "/foo",
-				handler: emptyHandler,
+				label:    "/foo",
+				handlers: methodHandlers{emptyHandler},
 			},
 			want: true,
 		},
@@ -196,7 +198,7 @@ func TestAddSingleRoute(t *testing.T) {
 	tree := loadTree("/api/v1/hello/world")
 
 	for _, test := range tests {
-		handler, _ := tree.getHandler(context.Background(), nodeLabel(test.route))
+		handler, _ := tree.getHandler(context.Background(), nodeLabel(test.route), 0)
 
 		if (handler != nil) != test.want {
 			t.Errorf("node.getHandler('%s'): %v, wanded %v", test.route, handler != nil, test.want)
@@ -222,6 +224,7 @@ func TestGetHandler(t *testing.T) {
 	}{
 		{"/api/v1/usecases/:type/:id", true},
 		{"/api/v1/users/:id/sites/*url", true},
+		{"/api/v1/users/:id/sites/", true},
 		{"/api/v1/users/:id/sites", true},
 		{"/api/v1/users/:id", true},
 		{"/logout", true},
@@ -232,7 +235,7 @@ func TestGetHandler(t *testing.T) {
 	}
 
 	for _, test := range tests {
-		handler, _ := tree.getHandler(context.Background(), nodeLabel(test.route))
+		handler, _ := tree.getHandler(context.Background(), nodeLabel(test.route), 0)
 
 		got := handler != nil
 
@@ -246,7 +249,7 @@ func loadTree(routes ...string) *node {
 	tree := &node{}
 
 	for _, route := range routes {
-		tree.insert(nodeLabel(route), emptyHandler)
+		tree.insert(nodeLabel(route), 0, emptyHandler)
 	}
 
 	return tree
@@ -259,7 +262,7 @@ func compareTrees(a, b *node) bool {
 func leaf(label string) *node {
 	return &node{
 		label:    nodeLabel(label),
-		handler:  emptyHandler,
+		handlers: methodHandlers{emptyHandler},
 		children: []*node{},
 	}
 }
@@ -267,7 +270,7 @@ func leaf(label string) *node {
 func branch(label string, children ...*node) *node {
 	return &node{
 		label:    nodeLabel(label),
-		handler:  nil,
+		handlers: nil,
 		children: children,
 	}
 }
@@ -275,7 +278,96 @@ func branch(label string, children ...*node) *node {
 func leafBranch(label string, children ...*node) *node {
 	return &node{
 		label:    nodeLabel(label),
-		handler:  emptyHandler,
+		handlers: methodHandlers{emptyHandler},
 		children: children,
 	}
 }
+
+func TestGetHandlerParams(t *testing.T) {
+	tree := loadTree(
+		"/users/:id",
+		"/users/:id/sites/*url",
+	)
+
+	tests := []struct {
+		route string
+		want  map[string]string
+	}{
+		{"/users/1", map[string]string{"id": "1"}},
+		{"/users/1/sites/example.com/foo", map[string]string{"id": "1", "url": "example.com/foo"}},
+		{"/users/1/sites/", map[string]string{"id": "1", "url": ""}},
+	}
+
+	for _, test := range tests {
+		_, ctx := tree.getHandler(context.Background(), nodeLabel(test.route), 0)
+		ps, _ := params.FromContext(ctx)
+
+		for key, want := range test.want {
+			if got, _ := ps.ByName(key); got != want {
+				t.Errorf("node.getHandler('%s'): got param %s='%s', wanted '%s'", test.route, key, got, want)
+			}
+		}
+	}
+}
+
+func TestInsertMethodHandlers(t *testing.T) {
+	tree := &node{}
+	tree.insert("/users/:id", 1, emptyHandler)
+	tree.insert("/users/:id", 4, emptyHandler)
+	tree.insert("/users/:id/sites/*url", 1, emptyHandler)
+	tree.insert("/users/:id/sites/*url", 6, emptyHandler)
+
+	want := branch("/users/", leafBranch(":id", branch("/sites/", leaf("*url"))))
+	if !compareTrees(tree, want) {
+		t.Errorf("node.insert(): got\n%s\n\nwanted:\n%s", tree, want)
+	}
+
+	for method, want := range map[int]bool{0: false, 1: true, 3: false, 4: true, 6: false} {
+		if handler, _ := tree.getHandler(context.Background(), "/users/42", method); (handler != nil) != want {
+			t.Errorf("node.getHandler('/users/42', %d): %v, wanted %v", method, handler != nil, want)
+		}
+	}
+
+	defer func() {
+		if recover() == nil {
+			t.Error("node.insert('/users/:id'): expected panic for a duplicate method, got none")
+		}
+	}()
+
+	tree.insert("/users/:id", 4, emptyHandler)
+}
+
+func TestInsertMethodConflicts(t *testing.T) {
+	tests := []struct {
+		routes  []string
+		methods []int
+		panics  bool
+	}{
+		{[]string{"/users/:id", "/users/search"}, []int{1, 3}, false},
+		{[]string{"/users/search", "/users/:id"}, []int{3, 1}, false},
+		{[]string{"/users/:id", "/users/:uid"}, []int{1, 4}, false},
+		{[]string{"/files/*path", "/files/:name"}, []int{1, 70}, false},
+		{[]string{"/files/*path", "/files/new"}, []int{1, 3}, false},
+		{[]string{"/users/:id", "/users/search"}, []int{1, 1}, true},
+		{[]string{"/users/:id", "/users/:uid"}, []int{70, 70}, true},
+		{[]string{"/users/:id", "/users/search", "/users/:uid"}, []int{1, 3, 3}, true},
+		{[]string{"/files/*path", "/files/new"}, []int{3, 3}, true},
+	}
+
+	for _, test := range tests {
+		var panicked bool
+
+		func() {
+			defer func() { panicked = recover() != nil }()
+
+			tree := &node{}
+			for i, route := range test.routes {
+				tree.insert(nodeLabel(route), test.methods[i], emptyHandler)
+			}
+		}()
+
+		if panicked != test.panics {
+			t.Errorf("node.insert(%q, %v): got panic %v, wanted %v", test.routes, test.methods, panicked, test.panics)
+		}
+	}
+}
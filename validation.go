@@ -0,0 +1,495 @@
+package hyper
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"math"
+	"net/http"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/bencicandrej/hyper-router/params"
+)
+
+// specSchema is the subset of the JSON Schema keywords the request validation supports.
+type specSchema struct {
+	Ref string `json:"$ref"`
+
+	Type     schemaTypes   `json:"type"`
+	Nullable bool          `json:"nullable"`
+	Enum     []interface{} `json:"enum"`
+
+	Minimum          *float64       `json:"minimum"`
+	Maximum          *float64       `json:"maximum"`
+	ExclusiveMinimum exclusiveBound `json:"exclusiveMinimum"`
+	ExclusiveMaximum exclusiveBound `json:"exclusiveMaximum"`
+
+	MinLength *int   `json:"minLength"`
+	MaxLength *int   `json:"maxLength"`
+	Pattern   string `json:"pattern"`
+
+	Items    *specSchema `json:"items"`
+	MinItems *int        `json:"minItems"`
+	MaxItems *int        `json:"maxItems"`
+
+	Properties           map[string]*specSchema `json:"properties"`
+	Required             []string               `json:"required"`
+	AdditionalProperties additionalProperties   `json:"additionalProperties"`
+
+	AllOf []*specSchema `json:"allOf"`
+	AnyOf []*specSchema `json:"anyOf"`
+	OneOf []*specSchema `json:"oneOf"`
+
+	// pattern is the compiled Pattern, set when the document is loaded.
+	pattern *regexp.Regexp
+}
+
+// schemaTypes is the type keyword, which is either a single type or a list of types.
+type schemaTypes []string
+
+func (types *schemaTypes) UnmarshalJSON(data []byte) error {
+	var single string
+	if err := json.Unmarshal(data, &single); err == nil {
+		*types = schemaTypes{single}
+		return nil
+	}
+
+	return json.Unmarshal(data, (*[]string)(types))
+}
+
+// additionalProperties is either a boolean or a schema of the additional properties.
+type additionalProperties struct {
+	Forbidden bool
+	Schema    *specSchema
+}
+
+func (additional *additionalProperties) UnmarshalJSON(data []byte) error {
+	var allowed bool
+	if err := json.Unmarshal(data, &allowed); err == nil {
+		additional.Forbidden = !allowed
+		return nil
+	}
+
+	return json.Unmarshal(data, &additional.Schema)
+}
+
+// exclusiveBound is either the exclusive bound itself, as in OpenAPI 3.1,
+// or a boolean making the minimum or maximum exclusive, as in OpenAPI 3.0.
+type exclusiveBound struct {
+	Value     *float64
+	Exclusive bool
+}
+
+func (bound *exclusiveBound) UnmarshalJSON(data []byte) error {
+	if err := json.Unmarshal(data, &bound.Exclusive); err == nil {
+		return nil
+	}
+
+	return json.Unmarshal(data, &bound.Value)
+}
+
+// validationProblem describes a single problem of an invalid request.
+type validationProblem struct {
+	In      string `json:"in"`
+	Name    string `json:"name,omitempty"`
+	Message string `json:"message"`
+}
+
+// validate is the middleware checking the requests against the operation.
+func (op *boundOperation) validate(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		problems := op.validateParameters(req)
+
+		if op.RequestBody != nil {
+			bodyProblems, err := op.validateBody(w, req)
+			if err != nil {
+				var tooLarge *http.MaxBytesError
+				if errors.As(err, &tooLarge) {
+					http.Error(w, err.Error(), http.StatusRequestEntityTooLarge)
+					return
+				}
+
+				http.Error(w, err.Error(), http.StatusBadRequest)
+				return
+			}
+
+			problems = append(problems, bodyProblems...)
+		}
+
+		if len(problems) > 0 {
+			w.Header().Set("Content-Type", "application/json")
+			w.WriteHeader(http.StatusBadRequest)
+
+			json.NewEncoder(w).Encode(struct {
+				Error    string              `json:"error"`
+				Problems []validationProblem `json:"problems"`
+			}{"invalid request", problems})
+			return
+		}
+
+		next.ServeHTTP(w, req)
+	})
+}
+
+func (op *boundOperation) validateParameters(req *http.Request) []validationProblem {
+	ps, _ := params.FromContext(req.Context())
+	query := req.URL.Query()
+
+	var problems []validationProblem
+	for _, parameter := range op.Parameters {
+		var values []string
+
+		switch parameter.In {
+		case "path":
+			if value, ok := ps.ByName(parameter.Name); ok {
+				values = []string{value}
+			}
+		case "query":
+			values = query[parameter.Name]
+		case "header":
+			values = req.Header.Values(parameter.Name)
+		default:
+			continue
+		}
+
+		if len(values) == 0 {
+			if parameter.Required || parameter.In == "path" {
+				problems = append(problems, validationProblem{parameter.In, parameter.Name, "is required"})
+			}
+
+			continue
+		}
+
+		if parameter.Schema == nil {
+			continue
+		}
+
+		schema, _ := op.spec.schema(parameter.Schema)
+
+		var value interface{}
+		if schema.Type.has("array") {
+			items := make([]interface{}, len(values))
+			for i, item := range values {
+				items[i] = op.spec.coerce(schema.Items, item)
+			}
+
+			value = items
+		} else {
+			value = op.spec.coerce(schema, values[0])
+		}
+
+		for _, message := range op.spec.validateValue(schema, value, "") {
+			problems = append(problems, validationProblem{parameter.In, parameter.Name, message})
+		}
+	}
+
+	return problems
+}
+
+// validateBody checks the JSON body against the schema of the request body,
+// and restores the body, so the handler can read it again. Bodies larger
+// than the limit of the operation fail with an *http.MaxBytesError. The
+// bodies without a schema are passed to the handler unread and unlimited.
+func (op *boundOperation) validateBody(w http.ResponseWriter, req *http.Request) ([]validationProblem, error) {
+	if op.body == nil {
+		if op.RequestBody.Required && !hasBody(req) {
+			return []validationProblem{{In: "body", Message: "is required"}}, nil
+		}
+
+		return nil, nil
+	}
+
+	var data []byte
+	if req.Body != nil {
+		var err error
+		if data, err = io.ReadAll(http.MaxBytesReader(w, req.Body, op.maxBodySize)); err != nil {
+			return nil, err
+		}
+
+		req.Body.Close()
+		req.Body = io.NopCloser(bytes.NewReader(data))
+	}
+
+	if len(data) == 0 {
+		if op.RequestBody.Required {
+			return []validationProblem{{In: "body", Message: "is required"}}, nil
+		}
+
+		return nil, nil
+	}
+
+	if !isJSONContentType(req.Header.Get("Content-Type")) {
+		return []validationProblem{{In: "body", Message: "content type must be application/json"}}, nil
+	}
+
+	decoder := json.NewDecoder(bytes.NewReader(data))
+	decoder.UseNumber()
+
+	var value interface{}
+	if err := decoder.Decode(&value); err != nil {
+		return []validationProblem{{In: "body", Message: "invalid JSON: " + err.Error()}}, nil
+	}
+
+	var problems []validationProblem
+	for _, message := range op.spec.validateValue(op.body, value, "") {
+		problems = append(problems, validationProblem{In: "body", Message: message})
+	}
+
+	return problems, nil
+}
+
+// hasBody checks if the request has a non-empty body. If the length of
+// the body is unknown, its first byte is read and put back in front of it.
+func hasBody(req *http.Request) bool {
+	if req.Body == nil || req.Body == http.NoBody {
+		return false
+	}
+
+	if req.ContentLength >= 0 {
+		return req.ContentLength > 0
+	}
+
+	var first [1]byte
+	n, _ := io.ReadFull(req.Body, first[:])
+	if n == 0 {
+		return false
+	}
+
+	req.Body = struct {
+		io.Reader
+		io.Closer
+	}{io.MultiReader(bytes.NewReader(first[:n]), req.Body), req.Body}
+
+	return true
+}
+
+// coerce converts the string value of a parameter to the type of the schema.
+func (s *spec) coerce(schema *specSchema, value string) interface{} {
+	if schema == nil {
+		return value
+	}
+
+	schema, err := s.schema(schema)
+	if err != nil {
+		return value
+	}
+
+	switch {
+	case schema.Type.has("integer") || schema.Type.has("number"):
+		if isJSONNumber(value) {
+			return json.Number(value)
+		}
+	case schema.Type.has("boolean"):
+		if b, err := strconv.ParseBool(value); err == nil {
+			return b
+		}
+	}
+
+	return value
+}
+
+// isJSONNumber checks if the value is a number of the JSON grammar in the
+// range of a float64. strconv.ParseFloat alone would also accept NaN,
+// Inf, hexadecimal numbers and underscores between the digits.
+func isJSONNumber(value string) bool {
+	if value == "" || value[0] != '-' && (value[0] < '0' || value[0] > '9') {
+		return false
+	}
+
+	if !json.Valid([]byte(value)) {
+		return false
+	}
+
+	_, err := strconv.ParseFloat(value, 64)
+
+	return err == nil
+}
+
+// validateValue checks the decoded JSON value against the schema, and
+// returns the problems found, prefixed with the location of the value.
+func (s *spec) validateValue(schema *specSchema, value interface{}, at string) []string {
+	if schema == nil {
+		return nil
+	}
+
+	schema, err := s.schema(schema)
+	if err != nil {
+		return []string{err.Error()}
+	}
+
+	problem := func(format string, args ...interface{}) []string {
+		message := fmt.Sprintf(format, args...)
+		if at != "" {
+			message = at + ": " + message
+		}
+
+		return []string{message}
+	}
+
+	if value == nil && (schema.Nullable || schema.Type.has("null")) {
+		return nil
+	}
+
+	if len(schema.Type) > 0 && !schema.Type.matches(value) {
+		return problem("must be of type %s", strings.Join(schema.Type, " or "))
+	}
+
+	if len(schema.Enum) > 0 && !inEnum(schema.Enum, value) {
+		return problem("must be one of the allowed values")
+	}
+
+	var problems []string
+
+	switch value := value.(type) {
+	case json.Number:
+		n, _ := value.Float64()
+		if schema.Minimum != nil && n < *schema.Minimum {
+			return problem("must be at least %v", *schema.Minimum)
+		}
+		if schema.Maximum != nil && n > *schema.Maximum {
+			return problem("must be at most %v", *schema.Maximum)
+		}
+		if min := schema.ExclusiveMinimum.Value; min != nil && n <= *min {
+			return problem("must be greater than %v", *min)
+		}
+		if max := schema.ExclusiveMaximum.Value; max != nil && n >= *max {
+			return problem("must be less than %v", *max)
+		}
+	case string:
+		length := utf8.RuneCountInString(value)
+		if schema.MinLength != nil && length < *schema.MinLength {
+			return problem("must be at least %d characters long", *schema.MinLength)
+		}
+		if schema.MaxLength != nil && length > *schema.MaxLength {
+			return problem("must be at most %d characters long", *schema.MaxLength)
+		}
+		if schema.pattern != nil && !schema.pattern.MatchString(value) {
+			return problem("must match the pattern %s", schema.Pattern)
+		}
+	case []interface{}:
+		if schema.MinItems != nil && len(value) < *schema.MinItems {
+			return problem("must have at least %d items", *schema.MinItems)
+		}
+		if schema.MaxItems != nil && len(value) > *schema.MaxItems {
+			return problem("must have at most %d items", *schema.MaxItems)
+		}
+
+		for i, item := range value {
+			problems = append(problems, s.validateValue(schema.Items, item, fmt.Sprintf("%s[%d]", at, i))...)
+		}
+	case map[string]interface{}:
+		for _, name := range schema.Required {
+			if _, ok := value[name]; !ok {
+				problems = append(problems, problem("missing required property %s", name)...)
+			}
+		}
+
+		names := make([]string, 0, len(value))
+		for name := range value {
+			names = append(names, name)
+		}
+
+		sort.Strings(names)
+
+		for _, name := range names {
+			property, ok := schema.Properties[name]
+			if !ok {
+				if schema.AdditionalProperties.Forbidden {
+					problems = append(problems, problem("unknown property %s", name)...)
+					continue
+				}
+
+				property = schema.AdditionalProperties.Schema
+			}
+
+			problems = append(problems, s.validateValue(property, value[name], joinLocation(at, name))...)
+		}
+	}
+
+	for _, sub := range schema.AllOf {
+		problems = append(problems, s.validateValue(sub, value, at)...)
+	}
+
+	if len(schema.AnyOf) > 0 && s.countValid(schema.AnyOf, value) == 0 {
+		problems = append(problems, problem("must match at least one of the anyOf schemas")...)
+	}
+
+	if len(schema.OneOf) > 0 && s.countValid(schema.OneOf, value) != 1 {
+		problems = append(problems, problem("must match exactly one of the oneOf schemas")...)
+	}
+
+	return problems
+}
+
+func (s *spec) countValid(schemas []*specSchema, value interface{}) int {
+	count := 0
+	for _, schema := range schemas {
+		if len(s.validateValue(schema, value, "")) == 0 {
+			count++
+		}
+	}
+
+	return count
+}
+
+func joinLocation(at, name string) string {
+	if at == "" {
+		return name
+	}
+
+	return at + "." + name
+}
+
+func (types schemaTypes) has(name string) bool {
+	for _, t := range types {
+		if t == name {
+			return true
+		}
+	}
+
+	return false
+}
+
+// matches checks if the decoded JSON value is of one of the types.
+func (types schemaTypes) matches(value interface{}) bool {
+	switch value := value.(type) {
+	case nil:
+		return types.has("null")
+	case bool:
+		return types.has("boolean")
+	case string:
+		return types.has("string")
+	case []interface{}:
+		return types.has("array")
+	case map[string]interface{}:
+		return types.has("object")
+	case json.Number:
+		if types.has("number") {
+			return true
+		}
+
+		n, err := value.Float64()
+
+		return err == nil && types.has("integer") && n == math.Trunc(n)
+	}
+
+	return false
+}
+
+// inEnum compares the value with the enum values, by their JSON encoding.
+func inEnum(enum []interface{}, value interface{}) bool {
+	encoded, _ := json.Marshal(value)
+	for _, allowed := range enum {
+		if other, _ := json.Marshal(allowed); bytes.Equal(encoded, other) {
+			return true
+		}
+	}
+
+	return false
+}
@@ -0,0 +1,178 @@
+package hyper
+
+import (
+	"context"
+	"fmt"
+	"mime"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+var versionKey ctxKey = 1
+
+// Versioning describes where the Router looks for the requested API version.
+//
+// The sources are checked in order: path prefix, header and Accept
+// media type parameter. The first one present in the request is used.
+type Versioning struct {
+	// PathPrefix selects the version from the first path segment,
+	// e.g. /v2/users. The prefix is removed before the route lookup.
+	PathPrefix bool
+	// Header is the name of the request header carrying the version,
+	// e.g. X-API-Version.
+	Header string
+	// AcceptParam is the name of the Accept media type parameter carrying
+	// the version, e.g. "version" for application/vnd.acme+json;version=2.
+	AcceptParam string
+	// Default is the version used for requests that do not specify one.
+	// If zero, the latest registered version is used.
+	Default int
+}
+
+// Version returns the route table of the API version. Routes registered
+// on it are served only for requests of that version, or of the higher
+// versions that do not define the route themselves, so each version
+// needs to register only the routes that changed.
+//
+// Routes registered on the Router itself serve requests of every version.
+func (r *Router) Version(version int) *Router {
+	if version <= 0 {
+		panic("version must be a positive number")
+	}
+
+	if table, ok := r.versions[version]; ok {
+		return table
+	}
+
+	if r.frozen != nil {
+		panic(fmt.Sprintf("can not add version %d to a frozen router", version))
+	}
+
+	if r.versions == nil {
+		r.versions = make(map[int]*Router)
+	}
+
+	table := NewRouter()
+	if r.cache != nil {
+		table.EnableCache(r.cache.size)
+	}
+
+	r.versions[version] = table
+	r.versionOrder = append(r.versionOrder, version)
+	sort.Sort(sort.Reverse(sort.IntSlice(r.versionOrder)))
+
+	return table
+}
+
+// VersionFromContext extracts the API version of the route serving the request.
+func VersionFromContext(ctx context.Context) (int, bool) {
+	version, ok := ctx.Value(versionKey).(int)
+
+	return version, ok
+}
+
+// serveVersion serves the request from the route table of the requested
+// version, falling back to the closest lower version that has the route.
+// The path is the request path with the version prefix removed.
+func (r *Router) serveVersion(w http.ResponseWriter, req *http.Request, requested int, path string) bool {
+	for _, version := range r.versionOrder {
+		if version > requested {
+			continue
+		}
+
+		if route, ctx := r.versions[version].getRoute(req.Context(), req.Method, path); route != nil {
+			serveRoute(w, req, context.WithValue(ctx, versionKey, version), route, r.CountHits)
+			return true
+		}
+	}
+
+	return false
+}
+
+// versionAllowed returns the methods allowed for the path by all
+// route tables a request of the version could be served from.
+func (r *Router) versionAllowed(requested int, path string) []string {
+	var methods []string
+	for _, version := range r.versionOrder {
+		if version <= requested {
+			methods = mergeMethods(methods, r.versions[version].allowed(path))
+		}
+	}
+
+	return methods
+}
+
+// requestVersion returns the API version requested, and the request
+// path with the version prefix removed.
+func (r *Router) requestVersion(req *http.Request) (int, string) {
+	path := req.URL.Path
+
+	if r.Versioning.PathPrefix {
+		segment := path[1:]
+		if end := strings.IndexByte(segment, '/'); end != -1 {
+			segment = segment[:end]
+		}
+
+		if version, ok := parseVersion(segment); ok && segment[0] == 'v' {
+			path = path[len(segment)+1:]
+			if path == "" {
+				path = "/"
+			}
+
+			return version, path
+		}
+	}
+
+	if r.Versioning.Header != "" {
+		if version, ok := parseVersion(req.Header.Get(r.Versioning.Header)); ok {
+			return version, path
+		}
+	}
+
+	if r.Versioning.AcceptParam != "" {
+		for _, accept := range strings.Split(req.Header.Get("Accept"), ",") {
+			_, mediaParams, err := mime.ParseMediaType(strings.TrimSpace(accept))
+			if err != nil {
+				continue
+			}
+
+			if version, ok := parseVersion(mediaParams[r.Versioning.AcceptParam]); ok {
+				return version, path
+			}
+		}
+	}
+
+	if r.Versioning.Default > 0 {
+		return r.Versioning.Default, path
+	}
+
+	return r.versionOrder[0], path
+}
+
+// parseVersion parses versions in the form of "2" or "v2".
+func parseVersion(value string) (int, bool) {
+	version, err := strconv.Atoi(strings.TrimPrefix(value, "v"))
+	if err != nil || version <= 0 {
+		return 0, false
+	}
+
+	return version, true
+}
+
+// mergeMethods returns a sorted union of the two sorted method lists.
+func mergeMethods(a, b []string) []string {
+	for _, method := range b {
+		i := sort.SearchStrings(a, method)
+		if i < len(a) && a[i] == method {
+			continue
+		}
+
+		a = append(a, "")
+		copy(a[i+1:], a[i:])
+		a[i] = method
+	}
+
+	return a
+}
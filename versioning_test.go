@@ -0,0 +1,82 @@
+package hyper
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestRouterVersioning(t *testing.T) {
+	router := NewRouter()
+	router.Versioning = Versioning{
+		PathPrefix:  true,
+		Header:      "X-API-Version",
+		AcceptParam: "version",
+		Default:     1,
+	}
+
+	router.Get("/health", methodHandler("health"))
+	router.Version(1).Get("/users", methodHandler("users v1"))
+	router.Version(1).Get("/sites", methodHandler("sites v1"))
+	router.Version(2).Get("/users", methodHandler("users v2"))
+	router.Version(3).Post("/users", methodHandler("create v3"))
+
+	tests := []struct {
+		method, path string
+		header       http.Header
+		code         int
+		body         string
+	}{
+		{http.MethodGet, "/users", nil, http.StatusOK, "users v1"},
+		{http.MethodGet, "/v2/users", nil, http.StatusOK, "users v2"},
+		{http.MethodGet, "/v2/sites", nil, http.StatusOK, "sites v1"},
+		{http.MethodGet, "/users", http.Header{"X-Api-Version": {"2"}}, http.StatusOK, "users v2"},
+		{http.MethodGet, "/users", http.Header{"Accept": {"application/vnd.acme+json;version=2"}}, http.StatusOK, "users v2"},
+		{http.MethodGet, "/v5/users", nil, http.StatusOK, "users v2"},
+		{http.MethodPost, "/v5/users", nil, http.StatusOK, "create v3"},
+		{http.MethodPost, "/v2/users", nil, http.StatusMethodNotAllowed, ""},
+		{http.MethodGet, "/health", http.Header{"X-Api-Version": {"3"}}, http.StatusOK, "health"},
+		{http.MethodGet, "/v1/health", nil, http.StatusOK, "health"},
+		{http.MethodPost, "/v1/health", nil, http.StatusMethodNotAllowed, ""},
+	}
+
+	for _, test := range tests {
+		req := httptest.NewRequest(test.method, test.path, nil)
+		for key, values := range test.header {
+			req.Header[key] = values
+		}
+
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+
+		if w.Code != test.code {
+			t.Errorf("%s %s %v: got status %d, wanted %d", test.method, test.path, test.header, w.Code, test.code)
+		}
+
+		if test.code == http.StatusOK && w.Body.String() != test.body {
+			t.Errorf("%s %s %v: got body '%s', wanted '%s'", test.method, test.path, test.header, w.Body.String(), test.body)
+		}
+	}
+}
+
+func TestRouterVersioningRootRoutes(t *testing.T) {
+	router := NewRouter()
+	router.Versioning = Versioning{PathPrefix: true}
+
+	router.Get("/health", methodHandler("health"))
+	router.Version(1).Get("/users", methodHandler("users v1"))
+
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/v1/health", nil))
+
+	if w.Code != http.StatusOK || w.Body.String() != "health" {
+		t.Errorf("GET /v1/health: got status %d and body '%s', wanted 200 and 'health'", w.Code, w.Body.String())
+	}
+
+	w = httptest.NewRecorder()
+	router.ServeHTTP(w, httptest.NewRequest(http.MethodPost, "/v1/health", nil))
+
+	if allow := w.Header().Get("Allow"); w.Code != http.StatusMethodNotAllowed || allow != http.MethodGet {
+		t.Errorf("POST /v1/health: got status %d and Allow '%s', wanted 405 and 'GET'", w.Code, allow)
+	}
+}
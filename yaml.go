@@ -0,0 +1,123 @@
+package hyper
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// plainYAMLKey matches the keys that do not need quoting in YAML.
+var plainYAMLKey = regexp.MustCompile(`^[A-Za-z_][A-Za-z0-9_.\-]*$`)
+
+// jsonToYAML converts the JSON document into a block style YAML
+// document, keeping the order of the object keys. The strings are
+// always double-quoted, so they never change their type in YAML.
+func jsonToYAML(data []byte) ([]byte, error) {
+	decoder := json.NewDecoder(bytes.NewReader(data))
+	decoder.UseNumber()
+
+	buff := &bytes.Buffer{}
+	if err := writeYAMLValue(buff, decoder, 0, ""); err != nil {
+		return nil, err
+	}
+
+	if _, err := decoder.Token(); err != io.EOF {
+		return nil, fmt.Errorf("unexpected data after the JSON document")
+	}
+
+	return buff.Bytes(), nil
+}
+
+// writeYAMLValue writes the next value of the decoder. The prefix is
+// written before the value, and is either the key, the list item
+// marker, or empty for the document root.
+func writeYAMLValue(buff *bytes.Buffer, decoder *json.Decoder, indent int, prefix string) error {
+	token, err := decoder.Token()
+	if err != nil {
+		return err
+	}
+
+	switch token {
+	case json.Delim('{'):
+		if !decoder.More() {
+			decoder.Token()
+			fmt.Fprintf(buff, "%s {}\n", prefix)
+			return nil
+		}
+
+		if prefix != "" {
+			fmt.Fprintln(buff, prefix)
+		}
+
+		for decoder.More() {
+			key, err := decoder.Token()
+			if err != nil {
+				return err
+			}
+
+			if err := writeYAMLValue(buff, decoder, indent+1, strings.Repeat("  ", indent)+yamlKey(key.(string))+":"); err != nil {
+				return err
+			}
+		}
+
+		_, err = decoder.Token()
+		return err
+	case json.Delim('['):
+		if !decoder.More() {
+			decoder.Token()
+			fmt.Fprintf(buff, "%s []\n", prefix)
+			return nil
+		}
+
+		if prefix != "" {
+			fmt.Fprintln(buff, prefix)
+		}
+
+		for decoder.More() {
+			if err := writeYAMLValue(buff, decoder, indent+1, strings.Repeat("  ", indent)+"-"); err != nil {
+				return err
+			}
+		}
+
+		_, err = decoder.Token()
+		return err
+	}
+
+	var scalar string
+	switch value := token.(type) {
+	case string:
+		scalar = strconv.Quote(value)
+	case json.Number:
+		scalar = value.String()
+	case bool:
+		scalar = strconv.FormatBool(value)
+	case nil:
+		scalar = "null"
+	}
+
+	if prefix == "" {
+		fmt.Fprintln(buff, scalar)
+	} else {
+		fmt.Fprintf(buff, "%s %s\n", prefix, scalar)
+	}
+
+	return nil
+}
+
+// yamlKey quotes the key, unless it is a plain YAML scalar.
+func yamlKey(key string) string {
+	switch key {
+	case "true", "false", "null", "yes", "no", "on", "off", "y", "n":
+		return strconv.Quote(key)
+	}
+
+	if plainYAMLKey.MatchString(key) {
+		return key
+	}
+
+	return strconv.Quote(key)
+}